@@ -119,7 +119,7 @@ func bootstrap(isDev bool,
 
 	bindingAddress := net.JoinHostPort(localConfig.InnerAddress.Ip, strconv.Itoa(localConfig.InnerAddress.Port))
 
-	migrationsDir, err := migrationsDirPath(isDev, localConfig)
+	migrationsDir, err := MigrationsDirPath(isDev, localConfig)
 	if err != nil {
 		return nil, errors.WithMessage(err, "resolve migrations dir path")
 	}
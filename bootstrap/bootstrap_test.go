@@ -1,10 +1,13 @@
 package bootstrap_test
 
 import (
+	"os"
+	"path"
 	"testing"
 
 	"github.com/Falokut/go-kit/bootstrap"
 	"github.com/Falokut/go-kit/cluster"
+	"github.com/stretchr/testify/require"
 )
 
 type RemoteConfig struct {
@@ -25,3 +28,22 @@ func TestNew(t *testing.T) {
 		Handler:          nil,
 	}})
 }
+
+func TestMigrationsDirPath(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir, err := bootstrap.MigrationsDirPath(true, bootstrap.LocalConfig{MigrationsDirPath: "config"})
+	require.NoError(err)
+	require.Equal("config", dir)
+
+	dir, err = bootstrap.MigrationsDirPath(true, bootstrap.LocalConfig{})
+	require.NoError(err)
+	require.Equal("./migrations", dir)
+
+	ex, err := os.Executable()
+	require.NoError(err)
+	dir, err = bootstrap.MigrationsDirPath(false, bootstrap.LocalConfig{})
+	require.NoError(err)
+	require.Equal(path.Join(path.Dir(ex), "migrations"), dir)
+}
@@ -133,7 +133,9 @@ func configFilePath(isDev bool) (string, error) {
 	return relativePathFromBin("config.yml")
 }
 
-func migrationsDirPath(isDev bool, cfg LocalConfig) (string, error) {
+// MigrationsDirPath returns migrationsDirPath of local config,
+// otherwise ./migrations in dev mode and migrations dir next to the binary
+func MigrationsDirPath(isDev bool, cfg LocalConfig) (string, error) {
 	if cfg.MigrationsDirPath != "" {
 		return cfg.MigrationsDirPath, nil
 	}
@@ -0,0 +1,182 @@
+// Command migrate manages db migrations of a service outside of its startup.
+//
+// Service local config (bootstrap.LocalConfig) is read with `db` (db.Config) section, only the latter is validated,
+// values may be also set via env. Migrations dir is resolved by bootstrap.MigrationsDirPath:
+// ./migrations in dev mode (APP_MODE=dev) and migrations dir next to the binary otherwise:
+//
+//	APP_CONFIG_PATH=conf/config.yml migrate status
+//	migrate -config conf/config.yml up-to 20240101000000
+//	migrate -config conf/config.yml down-to 0
+//	migrate -config conf/config.yml redo
+//	migrate -config conf/config.yml dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	stdlog "log"
+
+	"github.com/Falokut/go-kit/bootstrap"
+	"github.com/Falokut/go-kit/config"
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/db/migration"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/validator"
+	"github.com/pkg/errors"
+)
+
+const (
+	usage = `usage: migrate [flags] <command> [version]
+
+commands:
+  status          print status of all migrations
+  version         print current db version and the latest migration version
+  up              apply all pending migrations
+  up-to <ver>     apply pending migrations up to version
+  down-to <ver>   roll back migrations down to version
+  redo            roll back the latest migration and apply it again
+  dry-run         print sql of pending migrations
+
+flags:
+`
+)
+
+type Config struct {
+	// sections of local config, which are not used by the command, may be absent
+	bootstrap.LocalConfig `validate:"-"`
+
+	Db db.Config
+}
+
+func main() {
+	configPath := flag.String("config", os.Getenv("APP_CONFIG_PATH"), "path to local config file")
+	migrationsDir := flag.String("dir", "", "migrations dir, overrides migrationsDirPath from config")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2) // nolint:mnd
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err := run(ctx, *configPath, *migrationsDir, flag.Args())
+	if err != nil {
+		cancel()
+		stdlog.Fatal(err) // nolint:gocritic
+	}
+}
+
+func run(ctx context.Context, configPath string, migrationsDir string, args []string) error {
+	cfg, err := readConfig(configPath)
+	if err != nil {
+		return errors.WithMessage(err, "read config")
+	}
+	isDev := strings.ToLower(os.Getenv("APP_MODE")) == "dev"
+	if migrationsDir != "" {
+		cfg.MigrationsDirPath = migrationsDir
+	}
+	migrationsDir, err = bootstrap.MigrationsDirPath(isDev, cfg.LocalConfig)
+	if err != nil {
+		return errors.WithMessage(err, "resolve migrations dir path")
+	}
+
+	logger := log.New(log.WithEncoder(log.PlainTextEncoder{}), log.WithLevel(log.InfoLevel))
+	cli, err := db.Open(ctx, cfg.Db)
+	if err != nil {
+		return errors.WithMessage(err, "open db")
+	}
+	defer cli.Close()
+
+	runner := migration.NewRunner(migration.DialectPostgreSQL, migrationsDir, logger)
+	return execute(ctx, runner, cli, args)
+}
+
+// nolint:cyclop
+func execute(ctx context.Context, runner migration.Runner, cli *db.Client, args []string) error {
+	sqlDb := cli.DB.DB
+	command := args[0]
+	switch command {
+	case "status":
+		migrations, err := runner.Status(ctx, sqlDb)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			fmt.Println(migration.FormatStatus(m)) // nolint:forbidigo
+		}
+		return nil
+	case "version":
+		current, latest, err := runner.Version(ctx, sqlDb)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("db version: %d, latest migration: %d\n", current, latest) // nolint:forbidigo
+		return nil
+	case "up":
+		_, err := runner.UpTo(ctx, sqlDb, math.MaxInt64)
+		return err
+	case "up-to", "down-to":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if command == "up-to" {
+			_, err = runner.UpTo(ctx, sqlDb, version)
+		} else {
+			_, err = runner.DownTo(ctx, sqlDb, version)
+		}
+		return err
+	case "redo":
+		_, err := runner.Redo(ctx, sqlDb)
+		return err
+	case "dry-run":
+		return runner.DryRun(ctx, sqlDb, os.Stdout)
+	default:
+		return errors.Errorf("unknown command %q", command)
+	}
+}
+
+func versionArg(args []string) (int64, error) {
+	if len(args) < 2 { // nolint:mnd
+		return 0, errors.Errorf("version is required for %s", args[0])
+	}
+	version, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "parse version %q", args[1])
+	}
+	return version, nil
+}
+
+func readConfig(configPath string) (*Config, error) {
+	opts := []config.Option{
+		config.WithValidator(validator.Default),
+		config.WithEnvPrefix(os.Getenv("APP_CONFIG_ENV_PREFIX")),
+	}
+	if configPath != "" {
+		opts = append(opts, config.WithExtraSource(config.NewYamlConfig(configPath)))
+	}
+	source, err := config.New(opts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "create config")
+	}
+
+	cfg := &Config{}
+	err = source.Read(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
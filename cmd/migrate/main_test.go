@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfig(t *testing.T) {
+	require := require.New(t)
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	err := os.WriteFile(configPath, []byte(`
+moduleName: ignored
+migrationsDirPath: /opt/service/migrations
+db:
+  host: localhost
+  port: 5432
+  database: service
+`), 0600)
+	require.NoError(err)
+
+	cfg, err := readConfig(configPath)
+	require.NoError(err)
+	require.Equal("ignored", cfg.ModuleName)
+	require.Equal("/opt/service/migrations", cfg.MigrationsDirPath)
+	require.Equal("localhost", cfg.Db.Host)
+	require.Equal(5432, cfg.Db.Port)
+	require.Equal("service", cfg.Db.Database)
+
+	err = os.WriteFile(configPath, []byte("db:\n  host: localhost\n"), 0600)
+	require.NoError(err)
+	_, err = readConfig(configPath)
+	require.Error(err)
+}
+
+func TestVersionArg(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	version, err := versionArg([]string{"up-to", "20240101000000"})
+	require.NoError(err)
+	require.EqualValues(20240101000000, version)
+
+	_, err = versionArg([]string{"down-to"})
+	require.Error(err)
+	_, err = versionArg([]string{"down-to", "latest"})
+	require.Error(err)
+}
@@ -78,15 +78,41 @@ func Open(ctx context.Context, cfg Config, opts ...Option) (*Client, error) {
 	return dbCli, nil
 }
 
-func (db *Client) RunInTransaction(ctx context.Context, txFunc TxFunc, opts ...TxOption) (err error) {
-	options := &txOptions{}
+// RunInTransaction runs txFunc in a new transaction.
+// If ctx already holds a transaction of the same client, a savepoint is created instead
+func (db *Client) RunInTransaction(ctx context.Context, txFunc TxFunc, opts ...TxOption) error {
+	activeTx, ok := TxFromContext(ctx)
+	if ok && db.OwnsTx(activeTx) {
+		return activeTx.RunInTransaction(ctx, txFunc, opts...)
+	}
+
+	options := defaultTxOptions()
 	for _, opt := range opts {
 		opt(options)
 	}
-	tx, err := db.BeginTxx(ctx, options.nativeOpts)
+
+	for attempt := 1; ; attempt++ {
+		err := db.runInTransaction(ctx, txFunc, options)
+		if err == nil || attempt >= options.maxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(options.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.WithMessagef(err, "retry interrupted after %d attempts", attempt)
+		case <-timer.C:
+		}
+	}
+}
+
+func (db *Client) runInTransaction(ctx context.Context, txFunc TxFunc, options *txOptions) (err error) {
+	nativeTx, err := db.BeginTxx(ctx, options.nativeOpts)
 	if err != nil {
 		return errors.WithMessage(err, "begin transaction")
 	}
+	tx := &Tx{Tx: nativeTx, owner: db.DB}
 	defer func() {
 		p := recover()
 		if p != nil { // rollback and repanic
@@ -108,7 +134,12 @@ func (db *Client) RunInTransaction(ctx context.Context, txFunc TxFunc, opts ...T
 		}
 	}()
 
-	return txFunc(ctx, &Tx{tx})
+	return txFunc(txToContext(ctx, tx), tx)
+}
+
+// OwnsTx reports whether tx is started on db connections
+func (db *Client) OwnsTx(tx *Tx) bool {
+	return tx.owner == db.DB
 }
 
 func (db *Client) Select(ctx context.Context, ptr any, query string, args ...any) error {
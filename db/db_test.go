@@ -1,6 +1,8 @@
 package db_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"strconv"
 	"testing"
@@ -33,6 +35,71 @@ func TestOpen(t *testing.T) {
 	require.NoError(err)
 }
 
+func TestRunInTransaction_Nested(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	cli, err := db.Open(t.Context(), testConfig(t))
+	require.NoError(err)
+	defer cli.Close()
+
+	errNested := errors.New("nested error")
+	var count int
+	err = cli.RunInTransaction(t.Context(), func(ctx context.Context, tx *db.Tx) error {
+		_, err := tx.Exec(ctx, "CREATE TEMP TABLE nested_tx_test (id int) ON COMMIT DROP")
+		require.NoError(err)
+		_, err = db.FromContext(ctx, cli).Exec(ctx, "INSERT INTO nested_tx_test VALUES (1)")
+		require.NoError(err)
+
+		err = cli.RunInTransaction(ctx, func(ctx context.Context, nested *db.Tx) error {
+			require.Same(tx, nested)
+			_, err := nested.Exec(ctx, "INSERT INTO nested_tx_test VALUES (2)")
+			require.NoError(err)
+			return errNested
+		})
+		require.ErrorIs(err, errNested)
+
+		return tx.SelectRow(ctx, &count, "SELECT count(*) FROM nested_tx_test")
+	})
+	require.NoError(err)
+	require.Equal(1, count)
+}
+
+func TestFromContext_OtherClient(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	first, err := db.Open(t.Context(), testConfig(t))
+	require.NoError(err)
+	defer first.Close()
+	second, err := db.Open(t.Context(), testConfig(t))
+	require.NoError(err)
+	defer second.Close()
+
+	require.Same(first, db.FromContext(t.Context(), first))
+	err = first.RunInTransaction(t.Context(), func(ctx context.Context, tx *db.Tx) error {
+		require.Same(tx, db.FromContext(ctx, first))
+		require.Same(tx, db.FromContext(ctx, tx))
+		require.Same(second, db.FromContext(ctx, second))
+		return nil
+	})
+	require.NoError(err)
+}
+
+func testConfig(t *testing.T) db.Config {
+	t.Helper()
+
+	port, err := strconv.Atoi(envOrDefault("PG_PORT", "5432"))
+	require.NoError(t, err)
+	return db.Config{
+		Host:     envOrDefault("PG_HOST", "127.0.0.1"),
+		Port:     port,
+		Database: envOrDefault("PG_DB", "test"),
+		Username: envOrDefault("PG_USER", "test"),
+		Password: envOrDefault("PG_PASS", "test"),
+	}
+}
+
 func envOrDefault(name string, defValue string) string {
 	value := os.Getenv(name)
 	if value != "" {
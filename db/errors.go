@@ -0,0 +1,54 @@
+package db
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	ErrCodeSerializationFailure = "40001"
+	ErrCodeDeadlockDetected     = "40P01"
+	ErrCodeUniqueViolation      = "23505"
+	ErrCodeForeignKeyViolation  = "23503"
+	ErrCodeQueryCanceled        = "57014"
+)
+
+// PgErrorCode returns SQLSTATE code of postgres error wrapped in err
+func PgErrorCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	return pgErr.Code, true
+}
+
+func IsSerializationFailure(err error) bool {
+	return hasPgErrorCode(err, ErrCodeSerializationFailure)
+}
+
+func IsDeadlock(err error) bool {
+	return hasPgErrorCode(err, ErrCodeDeadlockDetected)
+}
+
+func IsUniqueViolation(err error) bool {
+	return hasPgErrorCode(err, ErrCodeUniqueViolation)
+}
+
+func IsForeignKeyViolation(err error) bool {
+	return hasPgErrorCode(err, ErrCodeForeignKeyViolation)
+}
+
+func IsQueryCanceled(err error) bool {
+	return hasPgErrorCode(err, ErrCodeQueryCanceled)
+}
+
+// IsRetryable reports whether transaction failed with err can be safely retried from the beginning
+func IsRetryable(err error) bool {
+	return IsSerializationFailure(err) || IsDeadlock(err)
+}
+
+func hasPgErrorCode(err error, code string) bool {
+	actual, ok := PgErrorCode(err)
+	return ok && actual == code
+}
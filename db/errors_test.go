@@ -0,0 +1,31 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	serializationErr := errors.WithMessage(&pgconn.PgError{Code: db.ErrCodeSerializationFailure}, "commit tx")
+	deadlockErr := errors.WithMessage(&pgconn.PgError{Code: db.ErrCodeDeadlockDetected}, "exec query")
+	uniqueErr := &pgconn.PgError{Code: db.ErrCodeUniqueViolation}
+
+	require.True(db.IsSerializationFailure(serializationErr))
+	require.True(db.IsRetryable(serializationErr))
+	require.True(db.IsDeadlock(deadlockErr))
+	require.True(db.IsRetryable(deadlockErr))
+	require.True(db.IsUniqueViolation(uniqueErr))
+	require.False(db.IsRetryable(uniqueErr))
+	require.False(db.IsRetryable(errors.New("some error")))
+
+	code, ok := db.PgErrorCode(deadlockErr)
+	require.True(ok)
+	require.Equal(db.ErrCodeDeadlockDetected, code)
+}
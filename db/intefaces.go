@@ -15,3 +15,8 @@ type DB interface {
 type Transactional interface {
 	RunInTransaction(ctx context.Context, txFunc TxFunc, opts ...TxOption) error
 }
+
+// TxOwner is implemented by clients which can tell whether transaction was started on their connections
+type TxOwner interface {
+	OwnsTx(tx *Tx) bool
+}
@@ -0,0 +1,5 @@
+package migration
+
+func UpSection(content []byte) (string, error) {
+	return upSection(content)
+}
@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultLockKey is a key of postgres advisory lock taken around migration application
+	DefaultLockKey int64 = 7_312_086_451_294_105_187
+
+	defaultLockTimeout      = 5 * time.Minute
+	defaultLockPollInterval = 500 * time.Millisecond
+)
+
+var (
+	ErrLockTimeout = errors.New("migration lock wait timeout")
+)
+
+type lockConfig struct {
+	disabled     bool
+	key          int64
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+func defaultLockConfig() lockConfig {
+	return lockConfig{
+		key:          DefaultLockKey,
+		timeout:      defaultLockTimeout,
+		pollInterval: defaultLockPollInterval,
+	}
+}
+
+// sessionLocker takes session advisory lock on the connection which goose uses to apply migrations,
+// so pods started at the same time apply migrations one by one.
+// Migrations are applied on the same connection, so a pool with a single connection does not deadlock
+type sessionLocker struct {
+	cfg    lockConfig
+	logger Logger
+}
+
+func (l sessionLocker) SessionLock(ctx context.Context, conn *sql.Conn) error {
+	waitCtx, cancel := context.WithTimeout(ctx, l.cfg.timeout)
+	defer cancel()
+
+	startedAt := time.Now()
+	for attempt := 0; ; attempt++ {
+		acquired := false
+		err := conn.QueryRowContext(waitCtx, "SELECT pg_try_advisory_lock($1)", l.cfg.key).Scan(&acquired)
+		if err != nil {
+			return l.lockWaitError(waitCtx, errors.WithMessage(err, "try advisory lock"))
+		}
+		if acquired {
+			break
+		}
+		if attempt == 0 {
+			l.logger.Info(ctx, fmt.Sprintf("migration lock %d is held by another instance, waiting", l.cfg.key))
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return l.lockWaitError(waitCtx, waitCtx.Err())
+		case <-time.After(l.cfg.pollInterval):
+		}
+	}
+	if waited := time.Since(startedAt); waited > l.cfg.pollInterval {
+		l.logger.Info(ctx, fmt.Sprintf("migration lock acquired after %s", waited.Round(time.Millisecond)))
+	}
+	return nil
+}
+
+func (l sessionLocker) SessionUnlock(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.cfg.key)
+	if err != nil {
+		return errors.WithMessage(err, "release migration lock")
+	}
+	return nil
+}
+
+func (l sessionLocker) lockWaitError(waitCtx context.Context, err error) error {
+	if errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return errors.WithMessagef(ErrLockTimeout, "key %d, timeout %s", l.cfg.key, l.cfg.timeout)
+	}
+	return err
+}
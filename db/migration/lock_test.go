@@ -0,0 +1,56 @@
+package migration_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/db/migration"
+	"github.com/Falokut/go-kit/test"
+	"github.com/Falokut/go-kit/test/dbt"
+)
+
+func TestRunner_ConcurrentRunsWithSingleConnection(t *testing.T) {
+	t.Parallel()
+	test, require := test.New(t)
+	testDb := dbt.New(test)
+	ctx := t.Context()
+
+	fsys := fstest.MapFS{
+		"00001_create_events.sql": {Data: []byte(`-- +goose Up
+CREATE TABLE events (id int PRIMARY KEY);
+SELECT pg_sleep(0.5);
+-- +goose Down
+DROP TABLE events;
+`)},
+		"00002_insert_event.sql": {Data: []byte(`-- +goose Up
+INSERT INTO events (id) VALUES (1);
+-- +goose Down
+DELETE FROM events;
+`)},
+	}
+
+	cfg := dbt.Config(test)
+	cfg.Schema = testDb.Schema()
+	cfg.MaxOpenConn = 1
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			cli, err := db.Open(ctx, cfg)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer cli.Close()
+			runner := migration.NewFsRunner(migration.DialectPostgreSQL, fsys, test.Logger(),
+				migration.WithLockTimeout(30*time.Second),
+			)
+			errs <- runner.Run(ctx, cli.DB.DB)
+		}()
+	}
+	for range 2 {
+		require.NoError(<-errs)
+	}
+	require.Equal(1, testDb.Must().Count(ctx, "SELECT count(*) FROM events"))
+}
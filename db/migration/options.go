@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+type Option func(r *Runner)
+
+// WithRefuseNewerSchema makes Run fail with ErrSchemaIsNewer
+// if db contains migrations which are newer than the latest known migration,
+// e.g. service was rolled back to a previous version
+func WithRefuseNewerSchema(refuse bool) Option {
+	return func(r *Runner) {
+		r.refuseNewerSchema = refuse
+	}
+}
+
+// WithProviderOptions passes extra options to goose provider, e.g. goose.WithAllowOutofOrder
+func WithProviderOptions(opts ...goose.ProviderOption) Option {
+	return func(r *Runner) {
+		r.providerOpts = append(r.providerOpts, opts...)
+	}
+}
+
+// WithGoMigrations registers migrations written in go, e.g. data backfills.
+// Versions must not overlap with versions of sql migrations
+//
+//	migration.WithGoMigrations(
+//		goose.NewGoMigration(20240101000000, &goose.GoFunc{RunTx: backfillUp}, &goose.GoFunc{RunTx: backfillDown}),
+//	)
+func WithGoMigrations(migrations ...*goose.Migration) Option {
+	return WithProviderOptions(goose.WithGoMigrations(migrations...))
+}
+
+// WithLockKey sets key of postgres advisory lock taken while migrations are applied.
+// Services sharing the same database with separate schemas may use different keys
+// to not wait for each other
+func WithLockKey(key int64) Option {
+	return func(r *Runner) {
+		r.lockCfg.key = key
+	}
+}
+
+// WithLockTimeout sets how long the runner waits for migration lock held by another instance.
+// ErrLockTimeout is returned if the lock is not acquired in time
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.lockCfg.timeout = timeout
+	}
+}
+
+// WithoutLock disables advisory lock around migration application,
+// e.g. if another goose.SessionLocker is passed by WithProviderOptions
+func WithoutLock() Option {
+	return func(r *Runner) {
+		r.lockCfg.disabled = true
+	}
+}
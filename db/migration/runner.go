@@ -1,11 +1,16 @@
 package migration
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,46 +23,80 @@ const (
 	DialectPostgreSQL = goose.DialectPostgres
 )
 
+var (
+	ErrSchemaIsNewer = errors.New("db schema version is newer than the latest migration")
+)
+
 type Logger interface {
 	Info(ctx context.Context, msg any, fields ...log.Field)
 }
 
 type Runner struct {
-	dialect      goose.Dialect
-	migrationDir string
-	logger       Logger
+	dialect           goose.Dialect
+	migrationDir      string
+	fsys              fs.FS
+	logger            Logger
+	refuseNewerSchema bool
+	providerOpts      []goose.ProviderOption
+	lockCfg           lockConfig
 }
 
+// NewRunner creates runner which reads migrations from migrationDir on local filesystem
 func NewRunner(
 	dialect goose.Dialect,
 	migrationDir string,
 	logger Logger,
+	opts ...Option,
 ) Runner {
-	return Runner{
+	r := Runner{
 		dialect:      dialect,
 		migrationDir: migrationDir,
 		logger:       logger,
+		lockCfg:      defaultLockConfig(),
 	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// NewFsRunner creates runner which reads migrations from root of fsys, e.g. embed.FS.
+// Use fs.Sub if migrations are placed in subdirectory.
+// fsys may be nil if only go migrations are used (see WithGoMigrations)
+func NewFsRunner(
+	dialect goose.Dialect,
+	fsys fs.FS,
+	logger Logger,
+	opts ...Option,
+) Runner {
+	r := Runner{
+		dialect: dialect,
+		fsys:    fsys,
+		logger:  logger,
+		lockCfg: defaultLockConfig(),
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
 }
 
 func (r Runner) Run(ctx context.Context, db *sql.DB, gooseOpts ...goose.ProviderOption) error {
 	ctx = log.ToContext(ctx, log.Any("worker", "goose_db_migration"))
 
-	_, err := os.Stat(r.migrationDir)
+	provider, err := r.provider(db, gooseOpts...)
 	if err != nil {
-		return errors.WithMessage(err, "get file info")
+		return err
 	}
 
-	provider, err := goose.NewProvider(r.dialect, db, os.DirFS(r.migrationDir), gooseOpts...)
-	if err != nil {
-		return errors.WithMessage(err, "get goose provider")
-	}
-
-	dbVersion, err := provider.GetDBVersion(ctx)
+	dbVersion, targetVersion, err := provider.GetVersions(ctx)
 	if err != nil {
 		return errors.WithMessage(err, "get db version")
 	}
 	r.logger.Info(ctx, fmt.Sprintf("current db version: %d", dbVersion))
+	if r.refuseNewerSchema && dbVersion > targetVersion {
+		return errors.WithMessagef(ErrSchemaIsNewer, "db version %d, latest migration %d", dbVersion, targetVersion)
+	}
 
 	migrations, err := provider.Status(ctx)
 	if err != nil {
@@ -68,26 +107,225 @@ func (r Runner) Run(ctx context.Context, db *sql.DB, gooseOpts ...goose.Provider
 		r.logger.Info(ctx, "no migrations")
 	}
 	for _, migration := range migrations {
-		appliedAt := "Pending"
-		if !migration.AppliedAt.IsZero() {
-			appliedAt = migration.AppliedAt.Format(time.RFC3339)
-		}
-		msg := fmt.Sprintf(
-			"migration: %s %s %s",
-			filepath.Base(migration.Source.Path),
-			strings.ToUpper(string(migration.State)),
-			appliedAt,
-		)
-		r.logger.Info(ctx, msg)
+		r.logger.Info(ctx, "migration: "+FormatStatus(migration))
 	}
 
 	result, err := provider.Up(ctx)
 	if err != nil {
 		return errors.WithMessage(err, "apply pending migrations")
 	}
-	for _, migrationResult := range result {
-		r.logger.Info(ctx, fmt.Sprintf("applied migration: %s", migrationResult.String()))
+	r.logResults(ctx, result...)
+
+	return nil
+}
+
+// Status returns status of all migrations ordered by version
+func (r Runner) Status(ctx context.Context, db *sql.DB, gooseOpts ...goose.ProviderOption) ([]*goose.MigrationStatus, error) {
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := provider.Status(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get status migrations")
+	}
+	return migrations, nil
+}
+
+// Version returns current db version and the latest migration version
+func (r Runner) Version(ctx context.Context, db *sql.DB, gooseOpts ...goose.ProviderOption) (int64, int64, error) {
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return 0, 0, err
+	}
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, errors.WithMessage(err, "get versions")
+	}
+	return current, target, nil
+}
+
+// UpTo applies pending migrations up to and including version
+func (r Runner) UpTo(
+	ctx context.Context,
+	db *sql.DB,
+	version int64,
+	gooseOpts ...goose.ProviderOption,
+) ([]*goose.MigrationResult, error) {
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := provider.UpTo(ctx, version)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "apply migrations up to %d", version)
+	}
+	r.logResults(ctx, result...)
+	return result, nil
+}
+
+// DownTo rolls back applied migrations down to version, migration with this version stays applied.
+// Use 0 to roll back all migrations
+func (r Runner) DownTo(
+	ctx context.Context,
+	db *sql.DB,
+	version int64,
+	gooseOpts ...goose.ProviderOption,
+) ([]*goose.MigrationResult, error) {
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := provider.DownTo(ctx, version)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "roll back migrations down to %d", version)
+	}
+	r.logResults(ctx, result...)
+	return result, nil
+}
+
+// Redo rolls back the latest applied migration and applies it again
+func (r Runner) Redo(ctx context.Context, db *sql.DB, gooseOpts ...goose.ProviderOption) ([]*goose.MigrationResult, error) {
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return nil, err
+	}
+	down, err := provider.Down(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "roll back latest migration")
+	}
+	r.logResults(ctx, down)
+
+	up, err := provider.ApplyVersion(ctx, down.Source.Version, true)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "apply migration %d", down.Source.Version)
+	}
+	r.logResults(ctx, up)
+
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+// DryRun writes up sql of pending migrations to w without applying them
+func (r Runner) DryRun(ctx context.Context, db *sql.DB, w io.Writer, gooseOpts ...goose.ProviderOption) error {
+	fsys, err := r.filesystem()
+	if err != nil {
+		return err
+	}
+	provider, err := r.provider(db, gooseOpts...)
+	if err != nil {
+		return err
+	}
+	migrations, err := provider.Status(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "get status migrations")
 	}
 
+	for _, migration := range migrations {
+		if migration.State != goose.StatePending {
+			continue
+		}
+		if migration.Source.Type != goose.TypeSQL {
+			_, err = fmt.Fprintf(w, "-- %d: go migration, sql is not available\n\n", migration.Source.Version)
+			if err != nil {
+				return errors.WithMessage(err, "write dry run output")
+			}
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, migration.Source.Path)
+		if err != nil {
+			return errors.WithMessagef(err, "read migration %s", migration.Source.Path)
+		}
+		up, err := upSection(content)
+		if err != nil {
+			return errors.WithMessagef(err, "parse migration %s", migration.Source.Path)
+		}
+		_, err = fmt.Fprintf(w, "-- %s\n%s\n", filepath.Base(migration.Source.Path), up)
+		if err != nil {
+			return errors.WithMessage(err, "write dry run output")
+		}
+	}
 	return nil
 }
+
+// FormatStatus formats migration status as '<file> <STATE> <applied at>'
+func FormatStatus(migration *goose.MigrationStatus) string {
+	appliedAt := "Pending"
+	if !migration.AppliedAt.IsZero() {
+		appliedAt = migration.AppliedAt.Format(time.RFC3339)
+	}
+	return fmt.Sprintf(
+		"%s %s %s",
+		filepath.Base(migration.Source.Path),
+		strings.ToUpper(string(migration.State)),
+		appliedAt,
+	)
+}
+
+func (r Runner) provider(db *sql.DB, gooseOpts ...goose.ProviderOption) (*goose.Provider, error) {
+	fsys, err := r.filesystem()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := append(slices.Clone(r.providerOpts), gooseOpts...)
+	if !r.lockCfg.disabled && r.dialect == DialectPostgreSQL {
+		opts = append(opts, goose.WithSessionLocker(sessionLocker{cfg: r.lockCfg, logger: r.logger}))
+	}
+	provider, err := goose.NewProvider(r.dialect, db, fsys, opts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get goose provider")
+	}
+	return provider, nil
+}
+
+// nolint:ireturn
+func (r Runner) filesystem() (fs.FS, error) {
+	if r.migrationDir == "" {
+		return r.fsys, nil
+	}
+
+	_, err := os.Stat(r.migrationDir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "get file info")
+	}
+	return os.DirFS(r.migrationDir), nil
+}
+
+func (r Runner) logResults(ctx context.Context, results ...*goose.MigrationResult) {
+	for _, migrationResult := range results {
+		r.logger.Info(ctx, fmt.Sprintf("applied migration: %s", migrationResult.String()))
+	}
+}
+
+// upSection returns statements between '-- +goose Up' and '-- +goose Down' annotations
+func upSection(content []byte) (string, error) {
+	builder := strings.Builder{}
+	inUp := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// a line can not be longer than the whole file
+	scanner.Buffer(nil, max(len(content)+1, bufio.MaxScanTokenSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		annotation := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(annotation, "-- +goose Up"):
+			inUp = true
+			continue
+		case strings.HasPrefix(annotation, "-- +goose Down"):
+			inUp = false
+			continue
+		case strings.HasPrefix(annotation, "-- +goose"):
+			continue
+		}
+		if inUp {
+			builder.WriteString(line)
+			builder.WriteByte('\n')
+		}
+	}
+	err := scanner.Err()
+	if err != nil {
+		return "", errors.WithMessage(err, "scan migration")
+	}
+	return builder.String(), nil
+}
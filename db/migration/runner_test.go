@@ -0,0 +1,97 @@
+package migration_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Falokut/go-kit/db/migration"
+	"github.com/Falokut/go-kit/test"
+	"github.com/Falokut/go-kit/test/dbt"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpSection(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	up, err := migration.UpSection([]byte(`-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (id int);
+-- +goose StatementEnd
+-- +goose Down
+DROP TABLE users;
+`))
+	require.NoError(err)
+	require.Equal("CREATE TABLE users (id int);\n", up)
+
+	longLine := "INSERT INTO users VALUES " + strings.Repeat("(1),", 100_000) + "(1);"
+	up, err = migration.UpSection([]byte("-- +goose Up\n" + longLine + "\n"))
+	require.NoError(err)
+	require.Equal(longLine+"\n", up)
+}
+
+func TestFsRunner_GoMigrations(t *testing.T) {
+	t.Parallel()
+	test, require := test.New(t)
+	testDb := dbt.New(test)
+	ctx := t.Context()
+
+	fsys := fstest.MapFS{
+		"00001_create_users.sql": {Data: []byte(`-- +goose Up
+CREATE TABLE users (id int PRIMARY KEY, name text);
+-- +goose Down
+DROP TABLE users;
+`)},
+	}
+	backfill := goose.NewGoMigration(2,
+		&goose.GoFunc{RunTx: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO users (id, name) VALUES (1, 'admin')")
+			return err
+		}},
+		&goose.GoFunc{RunTx: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = 1")
+			return err
+		}},
+	)
+	runner := migration.NewFsRunner(migration.DialectPostgreSQL, fsys, test.Logger(),
+		migration.WithGoMigrations(backfill),
+	)
+	sqlDb := testDb.Client.DB.DB
+
+	err := runner.Run(ctx, sqlDb)
+	require.NoError(err)
+	current, latest, err := runner.Version(ctx, sqlDb)
+	require.NoError(err)
+	require.EqualValues(2, current)
+	require.EqualValues(2, latest)
+	require.Equal(1, testDb.Must().Count(ctx, "SELECT count(*) FROM users"))
+
+	statuses, err := runner.Status(ctx, sqlDb)
+	require.NoError(err)
+	require.Len(statuses, 2)
+	require.Equal(goose.StateApplied, statuses[0].State)
+	require.Equal(goose.TypeSQL, statuses[0].Source.Type)
+	require.Equal(goose.StateApplied, statuses[1].State)
+	require.Equal(goose.TypeGo, statuses[1].Source.Type)
+
+	_, err = runner.DownTo(ctx, sqlDb, 1)
+	require.NoError(err)
+	current, _, err = runner.Version(ctx, sqlDb)
+	require.NoError(err)
+	require.EqualValues(1, current)
+	require.Equal(0, testDb.Must().Count(ctx, "SELECT count(*) FROM users"))
+	statuses, err = runner.Status(ctx, sqlDb)
+	require.NoError(err)
+	require.Equal(goose.StateApplied, statuses[0].State)
+	require.Equal(goose.StatePending, statuses[1].State)
+
+	_, err = runner.DownTo(ctx, sqlDb, 0)
+	require.NoError(err)
+	current, _, err = runner.Version(ctx, sqlDb)
+	require.NoError(err)
+	require.EqualValues(0, current)
+}
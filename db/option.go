@@ -1,6 +1,8 @@
 package db
 
 import (
+	"io/fs"
+
 	"github.com/Falokut/go-kit/db/migration"
 	"github.com/Falokut/go-kit/log"
 	"github.com/jackc/pgx/v5"
@@ -8,9 +10,16 @@ import (
 
 type Option func(cli *Client)
 
-func WithMigrationRunner(migrationDir string, logger log.Logger) Option {
+func WithMigrationRunner(migrationDir string, logger log.Logger, opts ...migration.Option) Option {
+	return func(db *Client) {
+		db.migrationRunner = migration.NewRunner(migration.DialectPostgreSQL, migrationDir, logger, opts...)
+	}
+}
+
+// WithFsMigrationRunner applies migrations from fsys, e.g. embed.FS, so binary does not depend on migrations directory
+func WithFsMigrationRunner(fsys fs.FS, logger log.Logger, opts ...migration.Option) Option {
 	return func(db *Client) {
-		db.migrationRunner = migration.NewRunner(migration.DialectPostgreSQL, migrationDir, logger)
+		db.migrationRunner = migration.NewFsRunner(migration.DialectPostgreSQL, fsys, logger, opts...)
 	}
 }
 
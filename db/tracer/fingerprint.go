@@ -0,0 +1,24 @@
+package tracer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// nolint:gochecknoglobals
+var (
+	stringLiteralRegexp  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteralRegexp  = regexp.MustCompile(`(\$\d+)|\b\d+(?:\.\d+)?\b`)
+	placeholderListRegex = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	lineCommentRegexp    = regexp.MustCompile(`--[^\n]*`)
+)
+
+// Fingerprint normalizes sql query, so queries which differ only by literal values have the same fingerprint.
+// Literals and positional arguments are replaced with '?', whitespaces are collapsed
+func Fingerprint(sql string) string {
+	sql = lineCommentRegexp.ReplaceAllString(sql, "")
+	sql = stringLiteralRegexp.ReplaceAllString(sql, "?")
+	sql = numberLiteralRegexp.ReplaceAllString(sql, "?")
+	sql = placeholderListRegex.ReplaceAllString(sql, "(?...)")
+	return strings.Join(strings.Fields(sql), " ")
+}
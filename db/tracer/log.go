@@ -0,0 +1,168 @@
+// Package tracer provides pgx tracers for db.Client: structured logging of queries and query latency metrics.
+//
+// Both tracers implement pgx.QueryTracer, pgx.BatchTracer, pgx.CopyFromTracer and pgx.ConnectTracer
+// and are registered via db.WithQueryTracer:
+//
+//	metrics := tracer.NewMetrics()
+//	infraServer.Handle("/internal/metrics/db", metrics.Handler())
+//	cli, err := db.Open(ctx, cfg, db.WithQueryTracer(tracer.NewLog(logger), metrics))
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/requestid"
+	"github.com/jackc/pgx/v5"
+)
+
+type logStartKey struct{}
+
+type logStart struct {
+	startedAt time.Time
+	sql       string
+	args      []any
+	batchLen  int
+}
+
+// Log writes traced queries to log.Logger
+// Argument values are never logged, only their types
+type Log struct {
+	logger             log.Logger
+	level              log.Level
+	slowQueryThreshold time.Duration
+}
+
+func NewLog(logger log.Logger, opts ...LogOption) Log {
+	t := Log{
+		logger: logger,
+		level:  log.DebugLevel,
+	}
+	for _, opt := range opts {
+		opt(&t)
+	}
+	return t
+}
+
+func (t Log) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, logStartKey{}, logStart{
+		startedAt: time.Now(),
+		sql:       data.SQL,
+		args:      data.Args,
+	})
+}
+
+func (t Log) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(logStartKey{}).(logStart)
+	if !ok {
+		return
+	}
+	t.write(ctx, "db: query", start, data.Err,
+		log.String("sql", start.sql),
+		log.Strings("args", redactArgs(start.args)),
+		log.Int64("rowsAffected", data.CommandTag.RowsAffected()),
+	)
+}
+
+func (t Log) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, logStartKey{}, logStart{
+		startedAt: time.Now(),
+		batchLen:  data.Batch.Len(),
+	})
+}
+
+func (t Log) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	t.logger.Log(ctx, t.levelFor(data.Err, 0), "db: batch query", t.fields(ctx, data.Err,
+		log.String("sql", data.SQL),
+		log.Strings("args", redactArgs(data.Args)),
+		log.Int64("rowsAffected", data.CommandTag.RowsAffected()),
+	)...)
+}
+
+func (t Log) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	start, ok := ctx.Value(logStartKey{}).(logStart)
+	if !ok {
+		return
+	}
+	t.write(ctx, "db: batch", start, data.Err, log.Int("batchLen", start.batchLen))
+}
+
+func (t Log) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return context.WithValue(ctx, logStartKey{}, logStart{
+		startedAt: time.Now(),
+		sql:       data.TableName.Sanitize(),
+	})
+}
+
+func (t Log) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	start, ok := ctx.Value(logStartKey{}).(logStart)
+	if !ok {
+		return
+	}
+	t.write(ctx, "db: copy from", start, data.Err,
+		log.String("table", start.sql),
+		log.Int64("rowsAffected", data.CommandTag.RowsAffected()),
+	)
+}
+
+func (t Log) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	return context.WithValue(ctx, logStartKey{}, logStart{
+		startedAt: time.Now(),
+		sql:       fmt.Sprintf("%s:%d/%s", data.ConnConfig.Host, data.ConnConfig.Port, data.ConnConfig.Database),
+	})
+}
+
+func (t Log) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	start, ok := ctx.Value(logStartKey{}).(logStart)
+	if !ok {
+		return
+	}
+	t.write(ctx, "db: connect", start, data.Err, log.String("target", start.sql))
+}
+
+func (t Log) write(ctx context.Context, msg string, start logStart, err error, fields ...log.Field) {
+	elapsed := time.Since(start.startedAt)
+	fields = append(fields, log.Int64("elapsedTimeMs", elapsed.Milliseconds()))
+	t.logger.Log(ctx, t.levelFor(err, elapsed), msg, t.fields(ctx, err, fields...)...)
+}
+
+func (t Log) fields(ctx context.Context, err error, fields ...log.Field) []log.Field {
+	requestId := requestid.FromContext(ctx)
+	if requestId != "" {
+		fields = append(fields, log.String("requestId", requestId))
+	}
+	if err != nil {
+		fields = append(fields, log.Error(err))
+		code, ok := db.PgErrorCode(err)
+		if ok {
+			fields = append(fields, log.String("pgErrorCode", code))
+		}
+	}
+	return fields
+}
+
+// levelFor returns log.ErrorLevel for unexpected errors,
+// log.WarnLevel for slow queries and errors caused by concurrent access or constraints
+func (t Log) levelFor(err error, elapsed time.Duration) log.Level {
+	switch {
+	case err != nil && (db.IsRetryable(err) || db.IsUniqueViolation(err) || db.IsForeignKeyViolation(err)):
+		return log.WarnLevel
+	case err != nil:
+		return log.ErrorLevel
+	case t.slowQueryThreshold > 0 && elapsed > t.slowQueryThreshold:
+		return log.WarnLevel
+	default:
+		return t.level
+	}
+}
+
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("<%T>", arg)
+	}
+	return redacted
+}
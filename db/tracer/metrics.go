@@ -0,0 +1,237 @@
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	OtherFingerprint   = "other"
+	ConnectFingerprint = "connect"
+	BatchFingerprint   = "batch"
+
+	defaultMaxFingerprints = 1000
+)
+
+// nolint:gochecknoglobals,mnd
+var defaultBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+type metricsStartKey struct{}
+
+type metricsStart struct {
+	startedAt   time.Time
+	fingerprint string
+}
+
+type Bucket struct {
+	UpperBoundMs float64
+	Count        uint64
+}
+
+// QueryStat is a snapshot of latency histogram for one query fingerprint
+// Buckets are cumulative, the last bucket has +Inf upper bound and is omitted, its count equals Count
+type QueryStat struct {
+	Fingerprint string
+	Count       uint64
+	ErrorsCount uint64
+	SumMs       float64
+	Buckets     []Bucket
+}
+
+type histogram struct {
+	count   atomic.Uint64
+	errors  atomic.Uint64
+	sumNs   atomic.Int64
+	buckets []atomic.Uint64
+}
+
+// Metrics aggregates per query fingerprint latency histograms
+type Metrics struct {
+	buckets         []time.Duration
+	maxFingerprints int
+
+	mu         *sync.RWMutex
+	histograms map[string]*histogram
+
+	fingerprints     *sync.Map
+	fingerprintsSize *atomic.Int64
+}
+
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	m := &Metrics{
+		buckets:          defaultBuckets,
+		maxFingerprints:  defaultMaxFingerprints,
+		mu:               &sync.RWMutex{},
+		histograms:       make(map[string]*histogram),
+		fingerprints:     &sync.Map{},
+		fingerprintsSize: &atomic.Int64{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	// buckets may be passed by caller, so they are sorted in a copy
+	m.buckets = slices.Clone(m.buckets)
+	slices.Sort(m.buckets)
+	return m
+}
+
+func (m *Metrics) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return m.start(ctx, m.fingerprint(data.SQL))
+}
+
+func (m *Metrics) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	m.observe(ctx, data.Err)
+}
+
+func (m *Metrics) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return m.start(ctx, BatchFingerprint)
+}
+
+func (m *Metrics) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (m *Metrics) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	m.observe(ctx, data.Err)
+}
+
+func (m *Metrics) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return m.start(ctx, "copy "+data.TableName.Sanitize())
+}
+
+func (m *Metrics) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	m.observe(ctx, data.Err)
+}
+
+func (m *Metrics) TraceConnectStart(ctx context.Context, _ pgx.TraceConnectStartData) context.Context {
+	return m.start(ctx, ConnectFingerprint)
+}
+
+func (m *Metrics) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	m.observe(ctx, data.Err)
+}
+
+// Snapshot returns current state of all histograms sorted by fingerprint
+func (m *Metrics) Snapshot() []QueryStat {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]QueryStat, 0, len(m.histograms))
+	for fingerprint, h := range m.histograms {
+		stat := QueryStat{
+			Fingerprint: fingerprint,
+			Count:       h.count.Load(),
+			ErrorsCount: h.errors.Load(),
+			SumMs:       msFloat(time.Duration(h.sumNs.Load())),
+			Buckets:     make([]Bucket, len(m.buckets)),
+		}
+		cumulative := uint64(0)
+		for i, bound := range m.buckets {
+			cumulative += h.buckets[i].Load()
+			stat.Buckets[i] = Bucket{UpperBoundMs: msFloat(bound), Count: cumulative}
+		}
+		result = append(result, stat)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Fingerprint < result[j].Fingerprint })
+	return result
+}
+
+// Handler exposes Snapshot as json, intended to be registered on infra.Server
+func (m *Metrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.EncodeInto(w, m.Snapshot())
+	})
+}
+
+func (m *Metrics) start(ctx context.Context, fingerprint string) context.Context {
+	return context.WithValue(ctx, metricsStartKey{}, metricsStart{
+		startedAt:   time.Now(),
+		fingerprint: fingerprint,
+	})
+}
+
+func (m *Metrics) observe(ctx context.Context, err error) {
+	start, ok := ctx.Value(metricsStartKey{}).(metricsStart)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.startedAt)
+
+	h := m.histogram(start.fingerprint)
+	h.count.Add(1)
+	h.sumNs.Add(int64(elapsed))
+	if err != nil {
+		h.errors.Add(1)
+	}
+	for i, bound := range m.buckets {
+		if elapsed <= bound {
+			h.buckets[i].Add(1)
+			return
+		}
+	}
+}
+
+func (m *Metrics) histogram(fingerprint string) *histogram {
+	m.mu.RLock()
+	h, ok := m.histograms[fingerprint]
+	m.mu.RUnlock()
+	if ok {
+		return h
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok = m.histograms[fingerprint]
+	if ok {
+		return h
+	}
+	if len(m.histograms) >= m.maxFingerprints && fingerprint != OtherFingerprint {
+		h, ok = m.histograms[OtherFingerprint]
+		if ok {
+			return h
+		}
+		fingerprint = OtherFingerprint
+	}
+	h = &histogram{buckets: make([]atomic.Uint64, len(m.buckets))}
+	m.histograms[fingerprint] = h
+	return h
+}
+
+// fingerprint caches normalized queries, since the same sql strings are executed over and over
+func (m *Metrics) fingerprint(sql string) string {
+	cached, ok := m.fingerprints.Load(sql)
+	if ok {
+		return cached.(string) // nolint:forcetypeassert
+	}
+	fingerprint := Fingerprint(sql)
+	if m.fingerprintsSize.Load() < int64(m.maxFingerprints) {
+		m.fingerprints.Store(sql, fingerprint)
+		m.fingerprintsSize.Add(1)
+	}
+	return fingerprint
+}
+
+func msFloat(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,39 @@
+package tracer
+
+import (
+	"time"
+
+	"github.com/Falokut/go-kit/log"
+)
+
+type LogOption func(t *Log)
+
+// WithLevel sets level for regular queries, default is log.DebugLevel
+func WithLevel(level log.Level) LogOption {
+	return func(t *Log) {
+		t.level = level
+	}
+}
+
+// WithSlowQueryThreshold enables logging of queries longer than threshold with log.WarnLevel
+func WithSlowQueryThreshold(threshold time.Duration) LogOption {
+	return func(t *Log) {
+		t.slowQueryThreshold = threshold
+	}
+}
+
+type MetricsOption func(m *Metrics)
+
+// WithBuckets overrides upper bounds of latency histogram buckets
+func WithBuckets(buckets ...time.Duration) MetricsOption {
+	return func(m *Metrics) {
+		m.buckets = buckets
+	}
+}
+
+// WithMaxFingerprints limits number of distinct tracked queries, the rest are aggregated under OtherFingerprint
+func WithMaxFingerprints(maxFingerprints int) MetricsOption {
+	return func(m *Metrics) {
+		m.maxFingerprints = maxFingerprints
+	}
+}
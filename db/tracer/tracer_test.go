@@ -0,0 +1,97 @@
+package tracer_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/db/tracer"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/requestid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	require.Equal(
+		"SELECT * FROM users WHERE id = ? AND name = ? AND id IN (?...)",
+		tracer.Fingerprint("SELECT *\n\tFROM users -- comment\nWHERE id = $1 AND name = 'it''s' AND id IN (1, 2, 3)"),
+	)
+	require.Equal(tracer.Fingerprint("SELECT 1 FROM table2"), tracer.Fingerprint("SELECT 2 FROM table2"))
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	metrics := tracer.NewMetrics(tracer.WithBuckets(time.Hour), tracer.WithMaxFingerprints(1))
+
+	ctx := metrics.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT $1"})
+	metrics.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = metrics.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT $2"})
+	metrics.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{}})
+	ctx = metrics.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "DELETE FROM users"})
+	metrics.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	stats := metrics.Snapshot()
+	require.Len(stats, 2)
+	require.Equal("SELECT ?", stats[0].Fingerprint)
+	require.EqualValues(2, stats[0].Count)
+	require.EqualValues(1, stats[0].ErrorsCount)
+	require.EqualValues(2, stats[0].Buckets[0].Count)
+	require.Equal(tracer.OtherFingerprint, stats[1].Fingerprint)
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(http.StatusOK, recorder.Code)
+	require.Contains(recorder.Body.String(), `"fingerprint":"SELECT ?"`)
+}
+
+func TestMetrics_WithBuckets(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	buckets := []time.Duration{time.Second, time.Millisecond, 10 * time.Millisecond}
+	metrics := tracer.NewMetrics(tracer.WithBuckets(buckets...))
+	require.Equal([]time.Duration{time.Second, time.Millisecond, 10 * time.Millisecond}, buckets)
+
+	ctx := metrics.TraceQueryStart(t.Context(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	metrics.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	stats := metrics.Snapshot()
+	require.Len(stats, 1)
+	upperBounds := make([]float64, 0, len(stats[0].Buckets))
+	for _, bucket := range stats[0].Buckets {
+		upperBounds = append(upperBounds, bucket.UpperBoundMs)
+	}
+	require.Equal([]float64{1, 10, 1000}, upperBounds)
+}
+
+func TestLog(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+	out := &bytes.Buffer{}
+	logger := log.New(log.WithOutput(out), log.WithLevel(log.InfoLevel))
+	logTracer := tracer.NewLog(logger, tracer.WithLevel(log.InfoLevel), tracer.WithSlowQueryThreshold(time.Hour))
+
+	ctx := requestid.ToContext(t.Context(), "req-1")
+	ctx = logTracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL:  "UPDATE users SET password = $1",
+		Args: []any{"secret"},
+	})
+	logTracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("UPDATE 3")})
+
+	logged := out.String()
+	require.Contains(logged, "INFO")
+	require.Contains(logged, "UPDATE users SET password = $1")
+	require.Contains(logged, "<string>")
+	require.NotContains(logged, "secret")
+	require.Contains(logged, `"rowsAffected": 3`)
+	require.Contains(logged, "req-1")
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// tracers dispatches pgx trace events to every registered tracer
+// Batch, copy, prepare and connect events are passed only to tracers implementing corresponding pgx interface
 type tracers []pgx.QueryTracer
 
 // nolint:fatcontext
@@ -22,3 +24,92 @@ func (t tracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.Tra
 		tracer.TraceQueryEnd(ctx, conn, data)
 	}
 }
+
+// nolint:fatcontext
+func (t tracers) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for _, tracer := range t {
+		batchTracer, ok := tracer.(pgx.BatchTracer)
+		if ok {
+			ctx = batchTracer.TraceBatchStart(ctx, conn, data)
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	for _, tracer := range t {
+		batchTracer, ok := tracer.(pgx.BatchTracer)
+		if ok {
+			batchTracer.TraceBatchQuery(ctx, conn, data)
+		}
+	}
+}
+
+func (t tracers) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	for _, tracer := range t {
+		batchTracer, ok := tracer.(pgx.BatchTracer)
+		if ok {
+			batchTracer.TraceBatchEnd(ctx, conn, data)
+		}
+	}
+}
+
+// nolint:fatcontext
+func (t tracers) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	for _, tracer := range t {
+		copyTracer, ok := tracer.(pgx.CopyFromTracer)
+		if ok {
+			ctx = copyTracer.TraceCopyFromStart(ctx, conn, data)
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	for _, tracer := range t {
+		copyTracer, ok := tracer.(pgx.CopyFromTracer)
+		if ok {
+			copyTracer.TraceCopyFromEnd(ctx, conn, data)
+		}
+	}
+}
+
+// nolint:fatcontext
+func (t tracers) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	for _, tracer := range t {
+		prepareTracer, ok := tracer.(pgx.PrepareTracer)
+		if ok {
+			ctx = prepareTracer.TracePrepareStart(ctx, conn, data)
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	for _, tracer := range t {
+		prepareTracer, ok := tracer.(pgx.PrepareTracer)
+		if ok {
+			prepareTracer.TracePrepareEnd(ctx, conn, data)
+		}
+	}
+}
+
+// nolint:fatcontext
+func (t tracers) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	for _, tracer := range t {
+		connectTracer, ok := tracer.(pgx.ConnectTracer)
+		if ok {
+			ctx = connectTracer.TraceConnectStart(ctx, data)
+		}
+	}
+	return ctx
+}
+
+func (t tracers) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	for _, tracer := range t {
+		connectTracer, ok := tracer.(pgx.ConnectTracer)
+		if ok {
+			connectTracer.TraceConnectEnd(ctx, data)
+		}
+	}
+}
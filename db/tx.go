@@ -3,16 +3,36 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRetryMinDelay = 10 * time.Millisecond
+	defaultRetryMaxDelay = time.Second
 )
 
 type txOptions struct {
-	nativeOpts *sql.TxOptions
+	nativeOpts    *sql.TxOptions
+	maxAttempts   int
+	retryMinDelay time.Duration
+	retryMaxDelay time.Duration
 }
 
 type TxOption func(options *txOptions)
 
+func defaultTxOptions() *txOptions {
+	return &txOptions{
+		maxAttempts:   1,
+		retryMinDelay: defaultRetryMinDelay,
+		retryMaxDelay: defaultRetryMaxDelay,
+	}
+}
+
 func IsolationLevel(level sql.IsolationLevel) TxOption {
 	return func(options *txOptions) {
 		if options.nativeOpts == nil {
@@ -31,10 +51,74 @@ func ReadOnly() TxOption {
 	}
 }
 
+// RetryOnConflict enables retry of the whole TxFunc on serialization failures and deadlocks (see IsRetryable).
+// maxAttempts includes the first attempt. TxFunc must be safe to run several times
+func RetryOnConflict(maxAttempts int) TxOption {
+	return func(options *txOptions) {
+		options.maxAttempts = max(maxAttempts, 1)
+	}
+}
+
+// RetryBackoff sets bounds of exponential backoff with full jitter between retry attempts
+func RetryBackoff(minDelay time.Duration, maxDelay time.Duration) TxOption {
+	return func(options *txOptions) {
+		options.retryMinDelay = minDelay
+		options.retryMaxDelay = max(minDelay, maxDelay)
+	}
+}
+
+// nolint:gosec
+func (o *txOptions) retryDelay(attempt int) time.Duration {
+	delay := o.retryMinDelay
+	for i := 1; i < attempt && delay < o.retryMaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.retryMaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay)
+}
+
 type TxFunc func(ctx context.Context, tx *Tx) error
 
 type Tx struct {
 	*sqlx.Tx
+
+	owner         *sqlx.DB
+	savepointsSeq int
+}
+
+type txContextKey struct{}
+
+// TxFromContext returns transaction started by RunInTransaction, if any
+func TxFromContext(ctx context.Context) (*Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*Tx)
+	return tx, ok
+}
+
+// FromContext returns active transaction from ctx if it is started on db connections (see TxOwner),
+// otherwise returns db
+// nolint:ireturn
+func FromContext(ctx context.Context, db DB) DB {
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return db
+	}
+	owner, ok := db.(TxOwner)
+	if ok && owner.OwnsTx(tx) {
+		return tx
+	}
+	return db
+}
+
+// OwnsTx reports whether tx and t are started on the same connections
+func (t *Tx) OwnsTx(tx *Tx) bool {
+	return tx.owner == t.owner
+}
+
+func txToContext(ctx context.Context, tx *Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
 }
 
 func (t *Tx) Select(ctx context.Context, ptr any, query string, args ...any) error {
@@ -52,3 +136,38 @@ func (t *Tx) Exec(ctx context.Context, query string, args ...any) (sql.Result, e
 func (t *Tx) ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error) {
 	return t.NamedExecContext(ctx, query, arg)
 }
+
+// RunInTransaction runs txFunc inside a savepoint of the current transaction.
+// On error or panic changes are rolled back to the savepoint, the outer transaction stays alive.
+// TxOption are ignored, savepoints inherit settings of the outer transaction
+func (t *Tx) RunInTransaction(ctx context.Context, txFunc TxFunc, _ ...TxOption) (err error) {
+	t.savepointsSeq++
+	savepoint := fmt.Sprintf("sp_%d", t.savepointsSeq)
+
+	_, err = t.ExecContext(ctx, "SAVEPOINT "+savepoint)
+	if err != nil {
+		return errors.WithMessage(err, "create savepoint")
+	}
+	defer func() {
+		p := recover()
+		if p != nil { // rollback to savepoint and repanic
+			_, _ = t.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+
+		if err != nil {
+			_, rbErr := t.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			if rbErr != nil {
+				err = errors.WithMessage(err, rbErr.Error())
+			}
+			return
+		}
+
+		_, err = t.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+		if err != nil {
+			err = errors.WithMessage(err, "release savepoint")
+		}
+	}()
+
+	return txFunc(txToContext(ctx, t), t)
+}
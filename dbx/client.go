@@ -3,12 +3,12 @@ package dbx
 import (
 	"context"
 	"database/sql"
-	"github.com/Falokut/go-kit/log"
 	"reflect"
 	"sync/atomic"
 	"time"
 
 	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/log"
 	"github.com/pkg/errors"
 )
 
@@ -18,24 +18,42 @@ var (
 
 const healthcheckTimeout = 500 * time.Millisecond
 
+// Client routes reads to replicas and writes to primary, see Route for details
+//
+// On Upgrade previous connections are not closed immediately:
+// they keep serving in-flight queries and transactions until these finish or drain timeout expires
 type Client struct {
-	options []db.Option
-	prevCfg *atomic.Value
-	cli     *atomic.Pointer[db.Client]
-	logger  log.Logger
+	options     []db.Option
+	prevCfg     *atomic.Value
+	cli         *atomic.Pointer[cluster]
+	logger      log.Logger
+	swapHandler *atomic.Pointer[SwapHandler]
 }
 
 func New(logger log.Logger, opts ...db.Option) *Client {
 	prevCfg := &atomic.Value{}
-	prevCfg.Store(db.Config{})
+	prevCfg.Store(Config{})
 	return &Client{
-		options: opts,
-		prevCfg: prevCfg,
-		cli:     &atomic.Pointer[db.Client]{},
-		logger:  logger,
+		options:     opts,
+		prevCfg:     prevCfg,
+		cli:         &atomic.Pointer[cluster]{},
+		logger:      logger,
+		swapHandler: &atomic.Pointer[SwapHandler]{},
 	}
 }
+
+// OnSwap sets handler which is called on connection swap events, e.g. to export metrics
+func (c *Client) OnSwap(handler SwapHandler) {
+	c.swapHandler.Store(&handler)
+}
+
+// Upgrade reinitializes client with single primary and without replicas
 func (c *Client) Upgrade(ctx context.Context, config db.Config) error {
+	return c.UpgradeCluster(ctx, Config{Primary: config})
+}
+
+// UpgradeCluster reinitializes client with primary and replicas
+func (c *Client) UpgradeCluster(ctx context.Context, config Config) error {
 	c.logger.Debug(ctx, "db client: received new config")
 
 	if reflect.DeepEqual(c.prevCfg.Load(), config) {
@@ -45,94 +63,146 @@ func (c *Client) Upgrade(ctx context.Context, config db.Config) error {
 
 	c.logger.Debug(ctx, "db client: initialization began")
 
-	opts := append([]db.Option{
-		db.WithCreateSchema(true),
-	}, c.options...)
-
-	newCli, err := db.Open(ctx, config, opts...)
+	newCluster, err := c.openCluster(ctx, config)
 	if err != nil {
-		return errors.WithMessage(err, "open new client")
+		c.logger.Error(ctx, "db client: open new connections, previous connections keep serving", log.Error(err))
+		c.reportSwap(ctx, SwapEvent{Type: SwapEventOpenFailed, Err: err})
+		return err
 	}
 
-	readOnly, err := newCli.IsReadOnly(ctx)
-	if err != nil {
-		return errors.WithMessage(err, "check is new cli read only")
-	}
-	if readOnly {
-		c.logger.Info(ctx, "db client: connection is in read-only mode")
+	interval := defaultReplicaHealthcheckInterval
+	if config.ReplicaHealthcheckIntervalSec > 0 {
+		interval = time.Duration(config.ReplicaHealthcheckIntervalSec) * time.Second
 	}
+	maxLag := time.Duration(config.MaxReplicationLagSec) * time.Second
+	newCluster.watchReplicas(ctx, c.logger, interval, maxLag)
 
-	oldCli := c.cli.Swap(newCli)
-	if oldCli != nil {
-		_ = oldCli.Close()
+	drainTimeout := defaultDrainTimeout
+	if config.DrainTimeoutSec > 0 {
+		drainTimeout = time.Duration(config.DrainTimeoutSec) * time.Second
 	}
-
-	c.logger.Debug(ctx, "db client: initialization done")
+	c.swap(ctx, newCluster, drainTimeout)
+	c.logger.Debug(ctx, "db client: initialization done", log.Int("replicas", len(newCluster.replicas)))
 
 	c.prevCfg.Store(config)
 
 	return nil
 }
 
+// DB returns primary client.
+// Returned client is not protected from closing on Upgrade, use Acquire for long operations
 func (c *Client) DB() (*db.Client, error) {
-	return c.db()
+	cluster, err := c.cluster()
+	if err != nil {
+		return nil, err
+	}
+	return cluster.primary, nil
+}
+
+// Acquire returns primary client which is not closed on Upgrade until release is called.
+// release must be called exactly once
+func (c *Client) Acquire() (*db.Client, func(), error) {
+	cluster, err := c.acquire()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster.primary, cluster.release, nil
 }
 
 func (c *Client) Select(ctx context.Context, ptr any, query string, args ...any) error {
-	cli, err := c.db()
+	cluster, err := c.acquire()
 	if err != nil {
 		return err
 	}
-	return cli.Select(ctx, ptr, query, args...)
+	defer cluster.release()
+	return cluster.reader(ctx).Select(ctx, ptr, query, args...)
 }
 
 func (c *Client) SelectRow(ctx context.Context, ptr any, query string, args ...any) error {
-	cli, err := c.db()
+	cluster, err := c.acquire()
 	if err != nil {
 		return err
 	}
-	return cli.SelectRow(ctx, ptr, query, args...)
+	defer cluster.release()
+	return cluster.reader(ctx).SelectRow(ctx, ptr, query, args...)
 }
 
 func (c *Client) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
-	cli, err := c.db()
+	cluster, err := c.acquire()
 	if err != nil {
 		return nil, err
 	}
-	return cli.Exec(ctx, query, args...)
+	defer cluster.release()
+	return cluster.writer(ctx).Exec(ctx, query, args...)
 }
 
 func (c *Client) ExecNamed(ctx context.Context, query string, arg any) (sql.Result, error) {
-	cli, err := c.db()
+	cluster, err := c.acquire()
 	if err != nil {
 		return nil, err
 	}
-	return cli.ExecNamed(ctx, query, arg)
+	defer cluster.release()
+	return cluster.writer(ctx).ExecNamed(ctx, query, arg)
 }
 
 func (c *Client) RunInTransaction(ctx context.Context, txFunc db.TxFunc, opts ...db.TxOption) error {
-	cli, err := c.db()
+	cluster, err := c.acquire()
 	if err != nil {
 		return err
 	}
-	return cli.RunInTransaction(ctx, txFunc, opts...)
+	defer cluster.release()
+	return cluster.writer(ctx).RunInTransaction(ctx, txFunc, opts...)
 }
 
+// OwnsTx reports whether tx is started on primary or replica connections of the current cluster
+func (c *Client) OwnsTx(tx *db.Tx) bool {
+	cluster, err := c.cluster()
+	if err != nil {
+		return false
+	}
+	if cluster.primary.OwnsTx(tx) {
+		return true
+	}
+	for _, replica := range cluster.replicas {
+		if replica.cli.OwnsTx(tx) {
+			return true
+		}
+	}
+	return false
+}
+
+// Close waits for in-flight operations to finish within drain timeout and closes connections
 func (c *Client) Close() error {
-	c.logger.Debug(context.Background(), "db client: call close")
-	c.prevCfg.Store(db.Config{})
-	oldCli := c.cli.Swap(nil)
-	if oldCli != nil {
-		return oldCli.Close()
+	ctx := context.Background()
+	c.logger.Debug(ctx, "db client: call close")
+	prevCfg, _ := c.prevCfg.Swap(Config{}).(Config)
+	oldCluster := c.cli.Swap(nil)
+	if oldCluster == nil {
+		return nil
 	}
-	return nil
+
+	drainTimeout := defaultDrainTimeout
+	if prevCfg.DrainTimeoutSec > 0 {
+		drainTimeout = time.Duration(prevCfg.DrainTimeoutSec) * time.Second
+	}
+	oldCluster.release()
+	select {
+	case <-oldCluster.drained:
+	case <-time.After(drainTimeout):
+		c.logger.Warn(ctx, "db client: drain timeout exceeded, closing connections with in-flight operations",
+			log.Int64("inFlight", oldCluster.refs.Load()),
+		)
+	}
+	return oldCluster.close()
 }
 
+// Healthcheck checks primary, unhealthy replicas are excluded from routing and do not fail healthcheck
 func (c *Client) Healthcheck(ctx context.Context) error {
-	cli, err := c.db()
+	cli, release, err := c.Acquire()
 	if err != nil {
 		return err
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(ctx, healthcheckTimeout)
 	defer cancel()
@@ -143,10 +213,56 @@ func (c *Client) Healthcheck(ctx context.Context) error {
 	return nil
 }
 
-func (c *Client) db() (*db.Client, error) {
-	oldCli := c.cli.Load()
-	if oldCli == nil {
+func (c *Client) openCluster(ctx context.Context, config Config) (*cluster, error) {
+	opts := append([]db.Option{
+		db.WithCreateSchema(true),
+	}, c.options...)
+
+	primary, err := db.Open(ctx, config.Primary, opts...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "open new client")
+	}
+
+	readOnly, err := primary.IsReadOnly(ctx)
+	if err != nil {
+		_ = primary.Close()
+		return nil, errors.WithMessage(err, "check is new cli read only")
+	}
+	if readOnly {
+		c.logger.Warn(ctx, "db client: primary connection is in read-only mode")
+	}
+
+	replicas := make([]*db.Client, 0, len(config.Replicas))
+	for i, replicaCfg := range config.Replicas {
+		cli, err := db.Open(ctx, replicaCfg, c.options...)
+		if err != nil {
+			_ = newCluster(primary, replicas, replicationLag).close()
+			return nil, errors.WithMessagef(err, "open replica %d", i)
+		}
+		replicas = append(replicas, cli)
+	}
+
+	return newCluster(primary, replicas, replicationLag), nil
+}
+
+// acquire returns current cluster with registered in-flight operation, caller must release it
+func (c *Client) acquire() (*cluster, error) {
+	for {
+		cluster, err := c.cluster()
+		if err != nil {
+			return nil, err
+		}
+		if cluster.acquire() {
+			return cluster, nil
+		}
+		// cluster was swapped and drained concurrently, retry with current one
+	}
+}
+
+func (c *Client) cluster() (*cluster, error) {
+	cluster := c.cli.Load()
+	if cluster == nil {
 		return nil, ErrClientIsNotInitialized
 	}
-	return oldCli, nil
+	return cluster, nil
 }
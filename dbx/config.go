@@ -0,0 +1,14 @@
+package dbx
+
+import (
+	"github.com/Falokut/go-kit/db"
+)
+
+type Config struct {
+	Primary  db.Config   `validate:"required" schema:"Основная база данных"`
+	Replicas []db.Config `schema:"Реплики для чтения"`
+
+	MaxReplicationLagSec          int `schema:"Максимальное отставание реплики в секундах,При превышении чтение идёт в другие реплики"`
+	ReplicaHealthcheckIntervalSec int `schema:"Интервал проверки состояния реплик в секундах"`                                              // default = 5
+	DrainTimeoutSec               int `schema:"Время ожидания завершения операций на старых соединениях при смене конфигурации в секундах"` // default = 30
+}
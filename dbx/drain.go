@@ -0,0 +1,108 @@
+package dbx
+
+import (
+	"context"
+	"time"
+
+	"github.com/Falokut/go-kit/log"
+)
+
+const defaultDrainTimeout = 30 * time.Second
+
+type SwapEventType string
+
+const (
+	// SwapEventSwapped new connections are opened and serve all new operations
+	SwapEventSwapped SwapEventType = "swapped"
+	// SwapEventOpenFailed new connections are not opened, previous connections keep serving
+	SwapEventOpenFailed SwapEventType = "open_failed"
+	// SwapEventDrained previous connections are closed after all in-flight operations finished
+	SwapEventDrained SwapEventType = "drained"
+	// SwapEventDrainTimeout previous connections are closed with in-flight operations after drain timeout
+	SwapEventDrainTimeout SwapEventType = "drain_timeout"
+)
+
+type SwapEvent struct {
+	Type SwapEventType
+	// InFlight is a number of operations on previous connections at the moment of event
+	InFlight int64
+	// Elapsed is a duration of draining for SwapEventDrained and SwapEventDrainTimeout
+	Elapsed time.Duration
+	Err     error
+}
+
+type SwapHandler func(ctx context.Context, event SwapEvent)
+
+// acquire registers in-flight operation, returns false if cluster is already drained
+func (c *cluster) acquire() bool {
+	for {
+		refs := c.refs.Load()
+		if refs <= 0 {
+			return false
+		}
+		if c.refs.CompareAndSwap(refs, refs+1) {
+			return true
+		}
+	}
+}
+
+func (c *cluster) release() {
+	if c.refs.Add(-1) == 0 {
+		close(c.drained)
+	}
+}
+
+// inFlight returns number of in-flight operations without reference owned by Client
+func (c *cluster) inFlight() int64 {
+	return max(c.refs.Load()-1, 0)
+}
+
+// swap makes newCluster current, previous cluster is drained in background
+func (c *Client) swap(ctx context.Context, newCluster *cluster, drainTimeout time.Duration) {
+	oldCluster := c.cli.Swap(newCluster)
+	event := SwapEvent{Type: SwapEventSwapped}
+	if oldCluster != nil {
+		event.InFlight = oldCluster.inFlight()
+		go c.drain(context.WithoutCancel(ctx), oldCluster, drainTimeout)
+	}
+	c.reportSwap(ctx, event)
+}
+
+// drain waits for in-flight operations to finish and closes connections.
+// Cluster must be already unreachable for new operations
+func (c *Client) drain(ctx context.Context, cluster *cluster, timeout time.Duration) {
+	inFlight := cluster.inFlight()
+	startedAt := time.Now()
+	cluster.release()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	event := SwapEvent{Type: SwapEventDrained}
+	select {
+	case <-cluster.drained:
+	case <-timer.C:
+		event.Type = SwapEventDrainTimeout
+		event.InFlight = cluster.refs.Load()
+		c.logger.Warn(ctx, "db client: drain timeout exceeded, closing connections with in-flight operations",
+			log.Int64("inFlight", event.InFlight),
+		)
+	}
+	event.Elapsed = time.Since(startedAt)
+	event.Err = cluster.close()
+	if event.Err != nil {
+		c.logger.Error(ctx, "db client: close previous connections", log.Error(event.Err))
+	}
+	c.logger.Debug(ctx, "db client: previous connections closed",
+		log.Int64("inFlightOnSwap", inFlight),
+		log.Duration("elapsed", event.Elapsed),
+	)
+	c.reportSwap(ctx, event)
+}
+
+func (c *Client) reportSwap(ctx context.Context, event SwapEvent) {
+	handler := c.swapHandler.Load()
+	if handler != nil {
+		(*handler)(ctx, event)
+	}
+}
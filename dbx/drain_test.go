@@ -0,0 +1,183 @@
+package dbx_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/dbx"
+	"github.com/stretchr/testify/require"
+)
+
+func isClosed(cli *db.Client) bool {
+	err := cli.PingContext(context.Background())
+	return err != nil && !errors.Is(err, errNoConnection)
+}
+
+func newPrimaryCluster() (*dbx.Cluster, *db.Client) {
+	primary := newFakeClient()
+	return dbx.NewTestCluster(primary, nil, newFakeLagProbe().probe), primary
+}
+
+func newSwapEvents(cli *dbx.Client) <-chan dbx.SwapEvent {
+	events := make(chan dbx.SwapEvent, 100)
+	cli.OnSwap(func(_ context.Context, event dbx.SwapEvent) {
+		events <- event
+	})
+	return events
+}
+
+func TestClient_SwapDrainsInFlight(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cli := dbx.New(testLogger())
+	events := newSwapEvents(cli)
+	oldCluster, oldPrimary := newPrimaryCluster()
+	cli.Swap(t.Context(), oldCluster, time.Minute)
+	require.Equal(dbx.SwapEventSwapped, (<-events).Type)
+
+	acquired, release, err := cli.Acquire()
+	require.NoError(err)
+	require.Same(oldPrimary, acquired)
+
+	newCluster, newPrimary := newPrimaryCluster()
+	cli.Swap(t.Context(), newCluster, time.Minute)
+	event := <-events
+	require.Equal(dbx.SwapEventSwapped, event.Type)
+	require.EqualValues(1, event.InFlight)
+
+	current, releaseCurrent, err := cli.Acquire()
+	require.NoError(err)
+	require.Same(newPrimary, current)
+	releaseCurrent()
+
+	time.Sleep(50 * time.Millisecond)
+	require.False(isClosed(oldPrimary))
+
+	release()
+	event = <-events
+	require.Equal(dbx.SwapEventDrained, event.Type)
+	require.NoError(event.Err)
+	require.True(isClosed(oldPrimary))
+	require.False(isClosed(newPrimary))
+}
+
+func TestClient_DrainTimeout(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cli := dbx.New(testLogger())
+	events := newSwapEvents(cli)
+	oldCluster, oldPrimary := newPrimaryCluster()
+	cli.Swap(t.Context(), oldCluster, time.Minute)
+	<-events
+
+	_, release, err := cli.Acquire()
+	require.NoError(err)
+	newCluster, _ := newPrimaryCluster()
+	cli.Swap(t.Context(), newCluster, 50*time.Millisecond)
+	<-events
+
+	event := <-events
+	require.Equal(dbx.SwapEventDrainTimeout, event.Type)
+	require.EqualValues(1, event.InFlight)
+	require.GreaterOrEqual(event.Elapsed, 50*time.Millisecond)
+	require.True(isClosed(oldPrimary))
+	release()
+}
+
+func TestClient_CloseWaitsForInFlight(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cli := dbx.New(testLogger())
+	cluster, primary := newPrimaryCluster()
+	cli.Swap(t.Context(), cluster, time.Minute)
+
+	_, release, err := cli.Acquire()
+	require.NoError(err)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- cli.Close()
+	}()
+	select {
+	case <-closed:
+		require.Fail("close must wait for in-flight operations")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.False(isClosed(primary))
+	_, _, err = cli.Acquire()
+	require.ErrorIs(err, dbx.ErrClientIsNotInitialized)
+
+	release()
+	require.NoError(<-closed)
+	require.True(isClosed(primary))
+}
+
+func TestClient_ConcurrentSwaps(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cli := dbx.New(testLogger())
+	firstCluster, firstPrimary := newPrimaryCluster()
+	cli.Swap(t.Context(), firstCluster, time.Minute)
+	primaries := []*db.Client{firstPrimary}
+
+	stop := make(chan struct{})
+	usedClosed := &atomic.Int64{}
+	operations := &atomic.Int64{}
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				primary, release, err := cli.Acquire()
+				if err != nil {
+					continue
+				}
+				if isClosed(primary) {
+					usedClosed.Add(1)
+				}
+				time.Sleep(time.Millisecond)
+				if isClosed(primary) {
+					usedClosed.Add(1)
+				}
+				operations.Add(1)
+				release()
+			}
+		}()
+	}
+
+	for range 20 {
+		cluster, primary := newPrimaryCluster()
+		primaries = append(primaries, primary)
+		cli.Swap(t.Context(), cluster, time.Minute)
+		time.Sleep(5 * time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+
+	require.Zero(usedClosed.Load())
+	require.Positive(operations.Load())
+	require.NoError(cli.Close())
+	require.Eventually(func() bool {
+		for _, primary := range primaries {
+			if !isClosed(primary) {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,35 @@
+package dbx
+
+import (
+	"context"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/log"
+)
+
+type Cluster = cluster
+
+func NewTestCluster(
+	primary *db.Client,
+	replicas []*db.Client,
+	probe func(ctx context.Context, cli *db.Client) (time.Duration, error),
+) *Cluster {
+	return newCluster(primary, replicas, probe)
+}
+
+func (c *cluster) Reader(ctx context.Context) *db.Client {
+	return c.reader(ctx)
+}
+
+func (c *cluster) Writer(ctx context.Context) *db.Client {
+	return c.writer(ctx)
+}
+
+func (c *cluster) CheckReplicas(ctx context.Context, logger log.Logger, maxLag time.Duration) {
+	c.checkReplicas(ctx, logger, maxLag)
+}
+
+func (c *Client) Swap(ctx context.Context, cluster *Cluster, drainTimeout time.Duration) {
+	c.swap(ctx, cluster, drainTimeout)
+}
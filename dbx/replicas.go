@@ -0,0 +1,168 @@
+package dbx
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultReplicaHealthcheckInterval = 5 * time.Second
+
+	replicationLagQuery = `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+)
+
+// lagProbe returns replication lag of replica
+type lagProbe func(ctx context.Context, cli *db.Client) (time.Duration, error)
+
+type replica struct {
+	cli     *db.Client
+	healthy *atomic.Bool
+}
+
+// cluster is a set of connections built from one Config.
+// refs counts in-flight operations plus one reference owned by Client while cluster is current,
+// drained is closed when refs reaches zero
+type cluster struct {
+	primary  *db.Client
+	replicas []replica
+	next     *atomic.Uint64
+	lagProbe lagProbe
+	cancel   context.CancelFunc
+	refs     atomic.Int64
+	drained  chan struct{}
+}
+
+func newCluster(primary *db.Client, replicas []*db.Client, probe lagProbe) *cluster {
+	c := &cluster{
+		primary:  primary,
+		replicas: make([]replica, 0, len(replicas)),
+		next:     &atomic.Uint64{},
+		lagProbe: probe,
+		drained:  make(chan struct{}),
+	}
+	c.refs.Store(1)
+	for _, cli := range replicas {
+		c.replicas = append(c.replicas, replica{
+			cli:     cli,
+			healthy: &atomic.Bool{},
+		})
+	}
+	return c
+}
+
+func (c *cluster) reader(ctx context.Context) *db.Client {
+	_, hasTx := db.TxFromContext(ctx)
+	if hasTx || RouteFromContext(ctx) == RoutePrimary {
+		return c.primary
+	}
+	return c.replicaOrPrimary()
+}
+
+func (c *cluster) writer(ctx context.Context) *db.Client {
+	_, hasTx := db.TxFromContext(ctx)
+	if !hasTx && RouteFromContext(ctx) == RouteReplica {
+		return c.replicaOrPrimary()
+	}
+	return c.primary
+}
+
+// replicaOrPrimary picks healthy replicas in round robin order
+func (c *cluster) replicaOrPrimary() *db.Client {
+	count := uint64(len(c.replicas))
+	if count == 0 {
+		return c.primary
+	}
+	start := c.next.Add(1)
+	for i := range count {
+		replica := c.replicas[(start+i)%count]
+		if replica.healthy.Load() {
+			return replica.cli
+		}
+	}
+	return c.primary
+}
+
+func (c *cluster) close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	var err error
+	for _, replica := range c.replicas {
+		closeErr := replica.cli.Close()
+		if closeErr != nil {
+			err = errors.WithMessage(closeErr, "close replica")
+		}
+	}
+	closeErr := c.primary.Close()
+	if closeErr != nil {
+		err = errors.WithMessage(closeErr, "close primary")
+	}
+	return err
+}
+
+// watchReplicas periodically checks replicas and excludes ones which are unavailable or lagging
+func (c *cluster) watchReplicas(ctx context.Context, logger log.Logger, interval time.Duration, maxLag time.Duration) {
+	c.checkReplicas(ctx, logger, maxLag)
+	if len(c.replicas) == 0 {
+		return
+	}
+
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.checkReplicas(ctx, logger, maxLag)
+			}
+		}
+	}()
+}
+
+func (c *cluster) checkReplicas(ctx context.Context, logger log.Logger, maxLag time.Duration) {
+	for i, replica := range c.replicas {
+		err := c.checkReplica(ctx, replica.cli, maxLag)
+		healthy := err == nil
+		wasHealthy := replica.healthy.Swap(healthy)
+		switch {
+		case !healthy && wasHealthy:
+			logger.Warn(ctx, "db client: replica is unhealthy", log.Int("replica", i), log.Error(err))
+		case healthy && !wasHealthy:
+			logger.Info(ctx, "db client: replica is healthy", log.Int("replica", i))
+		}
+	}
+}
+
+func (c *cluster) checkReplica(ctx context.Context, cli *db.Client, maxLag time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, healthcheckTimeout)
+	defer cancel()
+
+	lag, err := c.lagProbe(ctx, cli)
+	if err != nil {
+		return err
+	}
+	if maxLag > 0 && lag > maxLag {
+		return errors.Errorf("replication lag %s exceeds %s", lag, maxLag)
+	}
+	return nil
+}
+
+func replicationLag(ctx context.Context, cli *db.Client) (time.Duration, error) {
+	var lagSec float64
+	err := cli.SelectRow(ctx, &lagSec, replicationLagQuery)
+	if err != nil {
+		return 0, errors.WithMessage(err, "select replication lag")
+	}
+	return time.Duration(lagSec * float64(time.Second)), nil
+}
@@ -0,0 +1,162 @@
+package dbx_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/db"
+	"github.com/Falokut/go-kit/dbx"
+	"github.com/Falokut/go-kit/log"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+var errNoConnection = errors.New("no connection")
+
+type noConnector struct{}
+
+func (noConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errNoConnection
+}
+
+func (noConnector) Driver() driver.Driver {
+	return nil
+}
+
+// newFakeClient returns client which never connects, it is used as identity of primary or replica
+func newFakeClient() *db.Client {
+	return &db.Client{DB: sqlx.NewDb(sql.OpenDB(noConnector{}), "pgx")}
+}
+
+type fakeLagProbe struct {
+	lock sync.Mutex
+	lags map[*db.Client]time.Duration
+	errs map[*db.Client]error
+}
+
+func newFakeLagProbe() *fakeLagProbe {
+	return &fakeLagProbe{
+		lags: make(map[*db.Client]time.Duration),
+		errs: make(map[*db.Client]error),
+	}
+}
+
+func (p *fakeLagProbe) set(cli *db.Client, lag time.Duration, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.lags[cli] = lag
+	p.errs[cli] = err
+}
+
+func (p *fakeLagProbe) probe(_ context.Context, cli *db.Client) (time.Duration, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.lags[cli], p.errs[cli]
+}
+
+func testLogger() log.Logger {
+	return log.New(log.WithOutput(io.Discard))
+}
+
+func TestCluster_RoundRobinReplicas(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	primary, first, second := newFakeClient(), newFakeClient(), newFakeClient()
+	probe := newFakeLagProbe()
+	cluster := dbx.NewTestCluster(primary, []*db.Client{first, second}, probe.probe)
+	cluster.CheckReplicas(t.Context(), testLogger(), time.Second)
+
+	counts := map[*db.Client]int{}
+	for range 10 {
+		counts[cluster.Reader(t.Context())]++
+	}
+	require.Equal(map[*db.Client]int{first: 5, second: 5}, counts)
+	require.Same(primary, cluster.Writer(t.Context()))
+}
+
+func TestCluster_ReplicationLag(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	primary, lagging, healthy := newFakeClient(), newFakeClient(), newFakeClient()
+	probe := newFakeLagProbe()
+	probe.set(lagging, 10*time.Second, nil)
+	probe.set(healthy, 100*time.Millisecond, nil)
+	cluster := dbx.NewTestCluster(primary, []*db.Client{lagging, healthy}, probe.probe)
+	cluster.CheckReplicas(t.Context(), testLogger(), time.Second)
+
+	for range 4 {
+		require.Same(healthy, cluster.Reader(t.Context()))
+	}
+
+	// replica catches up
+	probe.set(lagging, 0, nil)
+	cluster.CheckReplicas(t.Context(), testLogger(), time.Second)
+	counts := map[*db.Client]int{}
+	for range 4 {
+		counts[cluster.Reader(t.Context())]++
+	}
+	require.Equal(map[*db.Client]int{lagging: 2, healthy: 2}, counts)
+
+	// lag is not limited without max lag
+	probe.set(lagging, time.Hour, nil)
+	cluster.CheckReplicas(t.Context(), testLogger(), 0)
+	counts = map[*db.Client]int{}
+	for range 4 {
+		counts[cluster.Reader(t.Context())]++
+	}
+	require.Equal(map[*db.Client]int{lagging: 2, healthy: 2}, counts)
+}
+
+func TestCluster_AllReplicasUnhealthy(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	primary, first, second := newFakeClient(), newFakeClient(), newFakeClient()
+	probe := newFakeLagProbe()
+	probe.set(first, 0, errors.New("connection refused"))
+	probe.set(second, time.Minute, nil)
+	cluster := dbx.NewTestCluster(primary, []*db.Client{first, second}, probe.probe)
+	cluster.CheckReplicas(t.Context(), testLogger(), time.Second)
+
+	require.Same(primary, cluster.Reader(t.Context()))
+	require.Same(primary, cluster.Writer(dbx.WithRoute(t.Context(), dbx.RouteReplica)))
+
+	noReplicas := dbx.NewTestCluster(primary, nil, probe.probe)
+	require.Same(primary, noReplicas.Reader(t.Context()))
+}
+
+func TestCluster_Route(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	primary, replica := newFakeClient(), newFakeClient()
+	probe := newFakeLagProbe()
+	cluster := dbx.NewTestCluster(primary, []*db.Client{replica}, probe.probe)
+	cluster.CheckReplicas(t.Context(), testLogger(), time.Second)
+
+	require.Equal(dbx.RouteAuto, dbx.RouteFromContext(t.Context()))
+	require.Same(replica, cluster.Reader(t.Context()))
+	require.Same(primary, cluster.Writer(t.Context()))
+
+	ctx := dbx.WithRoute(t.Context(), dbx.RoutePrimary)
+	require.Equal(dbx.RoutePrimary, dbx.RouteFromContext(ctx))
+	require.Same(primary, cluster.Reader(ctx))
+	require.Same(primary, cluster.Writer(ctx))
+
+	ctx = dbx.WithRoute(t.Context(), dbx.RouteReplica)
+	require.Equal(dbx.RouteReplica, dbx.RouteFromContext(ctx))
+	require.Same(replica, cluster.Reader(ctx))
+	require.Same(replica, cluster.Writer(ctx))
+
+	// route of nested context overrides parent one
+	ctx = dbx.WithRoute(ctx, dbx.RouteAuto)
+	require.Same(primary, cluster.Writer(ctx))
+}
@@ -0,0 +1,29 @@
+package dbx
+
+import (
+	"context"
+)
+
+type Route int
+
+const (
+	// RouteAuto sends reads to healthy replicas and writes to primary
+	RouteAuto Route = iota
+	// RoutePrimary sends all queries to primary
+	RoutePrimary
+	// RouteReplica sends all queries, including Exec and RunInTransaction, to healthy replicas.
+	// Falls back to primary when there are no healthy replicas
+	RouteReplica
+)
+
+type routeContextKey struct{}
+
+// WithRoute overrides routing of queries called with returned context
+func WithRoute(ctx context.Context, route Route) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, route)
+}
+
+func RouteFromContext(ctx context.Context) Route {
+	route, _ := ctx.Value(routeContextKey{}).(Route)
+	return route
+}
@@ -3,6 +3,7 @@ module github.com/Falokut/go-kit
 go 1.24.0
 
 require (
+	github.com/coder/websocket v1.8.13
 	github.com/go-faker/faker/v4 v4.6.1
 	github.com/go-playground/locales v0.14.1
 	github.com/go-playground/universal-translator v0.18.1
@@ -14,6 +15,7 @@ require (
 	github.com/jmoiron/sqlx v1.4.0
 	github.com/json-iterator/go v1.1.12
 	github.com/julienschmidt/httprouter v1.3.0
+	github.com/klauspost/compress v1.18.0
 	github.com/minio/minio-go/v7 v7.0.94
 	github.com/mitchellh/mapstructure v1.5.0
 	github.com/modern-go/reflect2 v1.0.2
@@ -22,15 +24,14 @@ require (
 	github.com/pressly/goose/v3 v3.24.2
 	github.com/quic-go/quic-go v0.53.0
 	github.com/stretchr/testify v1.10.0
+	github.com/tinylib/msgp v1.3.0
 	github.com/wk8/go-ordered-map/v2 v2.1.8
 	github.com/xeipuuv/gojsonschema v1.2.0
 	golang.org/x/tools v0.34.0
 )
 
 require (
-	github.com/coder/websocket v1.8.13 // indirect
 	github.com/philhofer/fwd v1.2.0 // indirect
-	github.com/tinylib/msgp v1.3.0 // indirect
 	go.uber.org/mock v0.5.2 // indirect
 )
 
@@ -46,7 +47,6 @@ require (
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
-	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.11 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Falokut/go-kit/json"
@@ -21,9 +22,10 @@ type Checker interface {
 }
 
 type Registry struct {
-	logger  log.Logger
-	toCheck map[string]Checker
-	mu      *sync.RWMutex
+	logger   log.Logger
+	toCheck  map[string]Checker
+	mu       *sync.RWMutex
+	notReady atomic.Bool
 }
 
 func NewRegistry(logger log.Logger) *Registry {
@@ -34,6 +36,17 @@ func NewRegistry(logger log.Logger) *Registry {
 	}
 }
 
+// SetReady sets readiness of service, not ready service fails healthcheck with 503,
+// e.g. while it is shutting down, so load balancers stop routing requests to it
+func (r *Registry) SetReady(ready bool) {
+	r.notReady.Store(!ready)
+}
+
+// Ready reports readiness set by SetReady, service is ready by default
+func (r *Registry) Ready() bool {
+	return !r.notReady.Load()
+}
+
 func (m *Registry) Register(name string, toCheck Checker) {
 	m.mu.Lock()
 	m.toCheck[name] = toCheck
@@ -49,6 +62,16 @@ func (r *Registry) handle(w http.ResponseWriter, req *http.Request) {
 	defer r.mu.RUnlock()
 
 	ctx := log.ToContext(req.Context(), log.Any("action", "healthcheck"))
+	w.Header().Set("Content-Type", "application/health+json")
+	if !r.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(Result{
+			Status:      StatusFail,
+			FailDetails: map[string]any{"readiness": "service is not ready"},
+		})
+		return
+	}
+
 	result := Result{
 		Status:      StatusPass,
 		FailDetails: make(map[string]any),
@@ -62,7 +85,6 @@ func (r *Registry) handle(w http.ResponseWriter, req *http.Request) {
 		result.Status = StatusFail
 	}
 
-	w.Header().Set("Content-Type", "application/health+json")
 	if len(result.FailDetails) > 0 {
 		r.logger.Error(ctx, "healthcheck error", log.Any("failDetails", result.FailDetails))
 		w.WriteHeader(http.StatusInternalServerError)
@@ -10,10 +10,16 @@ import (
 )
 
 const (
-	ErrCodeInvalidRange = 800
-	ErrCodeInternal     = 900
-	ErrCodeUnauthorized = 700
-	ErrCodeForbidden    = 701
+	ErrCodeInvalidRange  = 800
+	ErrCodeNotAcceptable = 801
+	ErrCodeTooLarge      = 802
+	ErrCodeUnsupported   = 803
+	ErrCodeInternal      = 900
+	ErrCodeUnavailable   = 901
+	ErrCodeTimeout       = 902
+	ErrCodeUnauthorized  = 700
+	ErrCodeForbidden     = 701
+	ErrCodeRateLimit     = 702
 )
 
 type Error struct {
@@ -24,6 +30,7 @@ type Error struct {
 	httpStatusCode int
 	cause          error
 	level          log.Level
+	fieldErrors    bool
 }
 
 func NewInternalServiceError(err error) Error {
@@ -44,10 +51,40 @@ func NewUnauthorizedError(errorMsg string) Error {
 	return New(http.StatusUnauthorized, ErrCodeUnauthorized, errorMsg, errors.New(errorMsg))
 }
 
+func NewNotAcceptableError(errorMsg string) Error {
+	return New(http.StatusNotAcceptable, ErrCodeNotAcceptable, errorMsg, errors.New(errorMsg)).
+		WithLogLevel(log.WarnLevel)
+}
+
+func NewRequestTooLargeError(errorMsg string) Error {
+	return New(http.StatusRequestEntityTooLarge, ErrCodeTooLarge, errorMsg, errors.New(errorMsg)).
+		WithLogLevel(log.WarnLevel)
+}
+
+func NewUnsupportedMediaTypeError(errorMsg string) Error {
+	return New(http.StatusUnsupportedMediaType, ErrCodeUnsupported, errorMsg, errors.New(errorMsg)).
+		WithLogLevel(log.WarnLevel)
+}
+
+func NewTooManyRequestsError(errorMsg string) Error {
+	return New(http.StatusTooManyRequests, ErrCodeRateLimit, errorMsg, errors.New(errorMsg)).
+		WithLogLevel(log.WarnLevel)
+}
+
 func NewRangeUnacceptableError(errorMsg string) Error {
 	return New(http.StatusRequestedRangeNotSatisfiable, ErrCodeInvalidRange, errorMsg, errors.New(errorMsg))
 }
 
+func NewServiceUnavailableError(errorMsg string, err error) Error {
+	return New(http.StatusServiceUnavailable, ErrCodeUnavailable, errorMsg, err).
+		WithLogLevel(log.WarnLevel)
+}
+
+func NewTimeoutError(errorMsg string, err error) Error {
+	return New(http.StatusGatewayTimeout, ErrCodeTimeout, errorMsg, err).
+		WithLogLevel(log.WarnLevel)
+}
+
 func New(
 	httpStatusCode int,
 	errorCode int,
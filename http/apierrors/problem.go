@@ -0,0 +1,138 @@
+package apierrors
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+
+	problemTypeBlank = "about:blank"
+)
+
+// Problem is a RFC 9457 problem details object.
+// ErrorCode, Errors and Details are extension members
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	ErrorCode int            `json:"errorCode,omitempty"`
+	Errors    []FieldProblem `json:"errors,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// FieldProblem describes invalid field of request, e.g. validation error
+type FieldProblem struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// ProblemOptions configures problem details writing
+type ProblemOptions struct {
+	// TypeBaseUri is a prefix of problem type, type is TypeBaseUri + "/" + ErrorCode,
+	// "about:blank" is used if empty
+	TypeBaseUri string
+	// Instance identifies occurrence of the problem, e.g. request id
+	Instance string
+}
+
+type problemOptionsContextKey struct{}
+
+// ProblemOptionsToContext enables writing errors as problem details for request
+func ProblemOptionsToContext(ctx context.Context, opts ProblemOptions) context.Context {
+	return context.WithValue(ctx, problemOptionsContextKey{}, opts)
+}
+
+// ProblemOptionsFromContext returns options if errors must be written as problem details
+func ProblemOptionsFromContext(ctx context.Context) (ProblemOptions, bool) {
+	opts, ok := ctx.Value(problemOptionsContextKey{}).(ProblemOptions)
+	return opts, ok
+}
+
+// Problem converts error to RFC 9457 problem details
+func (e Error) Problem(opts ProblemOptions) Problem {
+	problem := Problem{
+		Type:      problemTypeBlank,
+		Title:     http.StatusText(e.httpStatusCode),
+		Status:    e.httpStatusCode,
+		Detail:    e.ErrorMessage,
+		Instance:  opts.Instance,
+		ErrorCode: e.ErrorCode,
+	}
+	if opts.TypeBaseUri != "" {
+		problem.Type = strings.TrimSuffix(opts.TypeBaseUri, "/") + "/" + strconv.Itoa(e.ErrorCode)
+	}
+
+	if !e.fieldErrors {
+		problem.Details = e.Details
+		return problem
+	}
+	problem.Errors = make([]FieldProblem, 0, len(e.Details))
+	for field, detail := range e.Details {
+		problem.Errors = append(problem.Errors, FieldProblem{
+			Field:  field,
+			Detail: toString(detail),
+		})
+	}
+	sort.Slice(problem.Errors, func(i, j int) bool {
+		return problem.Errors[i].Field < problem.Errors[j].Field
+	})
+	return problem
+}
+
+// WriteProblem writes error as application/problem+json
+func (e Error) WriteProblem(w http.ResponseWriter, opts ProblemOptions) error {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(e.httpStatusCode)
+	err := json.EncodeInto(w, e.Problem(opts))
+	if err != nil {
+		return errors.WithMessage(err, "json encode problem")
+	}
+	return nil
+}
+
+// WithFieldErrors sets details of invalid request fields, e.g. validation errors,
+// which are written as "errors" member of problem details
+func (e Error) WithFieldErrors(details map[string]any) Error {
+	e.Details = details
+	e.fieldErrors = true
+	return e
+}
+
+// FromProblem converts problem details received from other service to Error
+func FromProblem(problem Problem, cause error) Error {
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	e := New(problem.Status, problem.ErrorCode, message, cause)
+	if len(problem.Errors) == 0 {
+		return e.WithDetails(problem.Details)
+	}
+	details := make(map[string]any, len(problem.Errors))
+	for _, fieldProblem := range problem.Errors {
+		details[fieldProblem.Field] = fieldProblem.Detail
+	}
+	return e.WithFieldErrors(details)
+}
+
+func toString(value any) string {
+	s, ok := value.(string)
+	if ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
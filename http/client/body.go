@@ -2,9 +2,13 @@ package client
 
 import (
 	"bytes"
-	"github.com/Falokut/go-kit/json"
+	"encoding/xml"
 	"io"
 	"net/http"
+	"net/url"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
 )
 
 type RequestBodyWriter interface {
@@ -20,6 +24,33 @@ func (j jsonRequest) Write(req *http.Request, w io.Writer) error {
 	return json.EncodeInto(w, j.value)
 }
 
+type xmlRequest struct {
+	value any
+}
+
+func (x xmlRequest) Write(req *http.Request, w io.Writer) error {
+	req.Header.Set("Content-Type", "application/xml")
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.WithMessage(err, "write xml header")
+	}
+	err = xml.NewEncoder(w).Encode(x.value)
+	if err != nil {
+		return errors.WithMessage(err, "encode xml")
+	}
+	return nil
+}
+
+type formRequest struct {
+	values url.Values
+}
+
+func (f formRequest) Write(req *http.Request, w io.Writer) error {
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err := io.WriteString(w, f.values.Encode())
+	return err
+}
+
 type plainRequest struct {
 	value []byte
 }
@@ -44,3 +75,15 @@ func (j jsonResponse) Read(r io.Reader) error {
 	}
 	return json.NewDecoder(r).Decode(j.ptr)
 }
+
+type xmlResponse struct {
+	ptr any
+}
+
+func (x xmlResponse) Read(r io.Reader) error {
+	err := xml.NewDecoder(r).Decode(x.ptr)
+	if err != nil {
+		return errors.WithMessage(err, "decode xml")
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Falokut/go-kit/http/client"
+	"github.com/stretchr/testify/require"
+)
+
+type xmlItem struct {
+	XMLName xml.Name `xml:"item"`
+	Id      int      `xml:"id,attr"`
+	Name    string   `xml:"name"`
+}
+
+func TestRequestBuilder_FormRequestBody(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		_, _ = io.WriteString(w, r.Header.Get("Content-Type")+" "+r.PostForm.Get("name")+" "+r.PostForm.Get("tag"))
+	}))
+	defer srv.Close()
+
+	body, _, err := client.New().Post(srv.URL).
+		FormRequestBody(url.Values{"name": {"a b&c"}, "tag": {"x"}}).
+		DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal("application/x-www-form-urlencoded a b&c x", string(body))
+}
+
+func TestRequestBuilder_MultipartRequestBody(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		files := r.MultipartForm.File["files"]
+		result := []string{r.FormValue("title")}
+		for _, file := range files {
+			f, _ := file.Open()
+			content, _ := io.ReadAll(f)
+			_ = f.Close()
+			result = append(result, file.Filename+":"+file.Header.Get("Content-Type")+":"+string(content))
+		}
+		_, _ = io.WriteString(w, strings.Join(result, ","))
+	}))
+	defer srv.Close()
+
+	multipartBody := client.NewMultipartBody().
+		Field("title", "report").
+		File("files", "a.bin", bytes.NewReader([]byte("binary"))).
+		FileWithContentType("files", `"b".txt`, "text/plain", strings.NewReader("text"))
+	body, status, err := client.New().Post(srv.URL).
+		MultipartRequestBody(multipartBody).
+		DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.Equal(`report,a.bin:application/octet-stream:binary,"b".txt:text/plain:text`, string(body))
+}
+
+func TestRequestBuilder_Xml(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		item := xmlItem{}
+		err := xml.NewDecoder(r.Body).Decode(&item)
+		if err != nil || r.Header.Get("Content-Type") != "application/xml" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		item.Name += " updated"
+		_ = xml.NewEncoder(w).Encode(item)
+	}))
+	defer srv.Close()
+
+	response := xmlItem{}
+	err := client.New().Put(srv.URL).
+		XmlRequestBody(xmlItem{Id: 1, Name: "item"}).
+		XmlResponseBody(&response).
+		StatusCodeToError().
+		DoWithoutResponse(context.Background())
+	require.NoError(err)
+	require.Equal(1, response.Id)
+	require.Equal("item updated", response.Name)
+}
+
+func TestRequestBuilder_DoStream(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	content := bytes.Repeat([]byte("0123456789"), 1<<16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "not found")
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	cli := client.New()
+	stream, err := cli.Get(srv.URL).DoStream(context.Background())
+	require.NoError(err)
+	data, err := io.ReadAll(stream)
+	require.NoError(err)
+	require.NoError(stream.Close())
+	require.NoError(stream.Close())
+	require.Equal(content, data)
+
+	_, err = cli.Get(srv.URL + "/missing").DoStream(context.Background())
+	errResp := client.ErrorResponse{}
+	require.ErrorAs(err, &errResp)
+	require.Equal(http.StatusNotFound, errResp.StatusCode)
+	require.Equal("not found", string(errResp.Body))
+
+	resp, err := cli.Get(srv.URL).Do(context.Background())
+	require.NoError(err)
+	stream, err = resp.BodyReader()
+	require.NoError(err)
+	_, err = resp.Body()
+	require.ErrorIs(err, client.ErrBodyStreamed)
+	require.NoError(stream.Close())
+}
+
+type closeTrackingTransport struct {
+	closed atomic.Bool
+}
+
+func (t *closeTrackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = closeTrackingBody{ReadCloser: resp.Body, closed: &t.closed}
+	return resp, nil
+}
+
+type closeTrackingBody struct {
+	io.ReadCloser
+	closed *atomic.Bool
+}
+
+func (b closeTrackingBody) Close() error {
+	b.closed.Store(true)
+	return b.ReadCloser.Close()
+}
+
+func TestRequestBuilder_ErrorResponseClosesBody(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, "internal error")
+	}))
+	defer srv.Close()
+
+	transport := &closeTrackingTransport{}
+	cli := client.NewWithClient(&http.Client{Transport: transport})
+	_, err := cli.Get(srv.URL).StatusCodeToError().Do(context.Background())
+	errResp := client.ErrorResponse{}
+	require.ErrorAs(err, &errResp)
+	require.Equal("internal error", string(errResp.Body))
+	require.True(transport.closed.Load())
+}
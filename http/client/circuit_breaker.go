@@ -0,0 +1,347 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultCircuitWindow           = 10 * time.Second
+	defaultCircuitWindowBuckets    = 10
+	defaultCircuitFailureRate      = 0.5
+	defaultCircuitMinRequests      = 20
+	defaultCircuitOpenTimeout      = 30 * time.Second
+	defaultCircuitHalfOpenRequests = 1
+)
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// CircuitOpenError is returned without sending request if circuit of the host is open,
+// errors.Is(err, ErrCircuitOpen) is true
+// nolint:errname
+type CircuitOpenError struct {
+	Host string
+	// OpenUntil is a time when probe requests are allowed, zero if circuit is half-open and probes are in progress
+	OpenUntil time.Time
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open: host=%s", e.Host)
+}
+
+func (e CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen // nolint:errorlint
+}
+
+// FailurePredicate reports whether attempt finished with resp or err is a failure of the host,
+// resp may be nil if err is not nil
+type FailurePredicate func(resp *Response, err error) bool
+
+// StateChangeCallback is called after circuit of the host changes state
+type StateChangeCallback func(host string, from CircuitState, to CircuitState)
+
+type circuitBreakerOptions struct {
+	window           time.Duration
+	windowBuckets    int
+	failureRate      float64
+	minRequests      int
+	openTimeout      time.Duration
+	halfOpenRequests int
+	isFailure        FailurePredicate
+	onStateChange    []StateChangeCallback
+	now              func() time.Time
+}
+
+type CircuitBreakerOption func(options *circuitBreakerOptions)
+
+// WithFailureRate opens circuit if at least minRequests are completed during window
+// and rate of failures is greater or equal to failureRate, default 50% of 20 requests during 10s.
+// Non-positive window is replaced by default one
+func WithFailureRate(failureRate float64, minRequests int, window time.Duration) CircuitBreakerOption {
+	return func(options *circuitBreakerOptions) {
+		options.failureRate = failureRate
+		options.minRequests = max(minRequests, 1)
+		options.window = defaultCircuitWindow
+		if window > 0 {
+			// each bucket of window has to be at least 1ns long
+			options.window = max(window, time.Duration(options.windowBuckets))
+		}
+	}
+}
+
+// WithOpenTimeout sets time while circuit is open before probe requests are allowed, default 30s
+func WithOpenTimeout(openTimeout time.Duration) CircuitBreakerOption {
+	return func(options *circuitBreakerOptions) {
+		options.openTimeout = openTimeout
+	}
+}
+
+// WithHalfOpenRequests sets count of probe requests in half-open state,
+// circuit is closed after all of them succeed and opened again after any failure, default 1
+func WithHalfOpenRequests(halfOpenRequests int) CircuitBreakerOption {
+	return func(options *circuitBreakerOptions) {
+		options.halfOpenRequests = max(halfOpenRequests, 1)
+	}
+}
+
+// WithFailurePredicate replaces DefaultFailurePredicate
+func WithFailurePredicate(predicate FailurePredicate) CircuitBreakerOption {
+	return func(options *circuitBreakerOptions) {
+		options.isFailure = predicate
+	}
+}
+
+// WithStateChangeCallback appends callback called on each state change, e.g. for logging or metrics
+func WithStateChangeCallback(callback StateChangeCallback) CircuitBreakerOption {
+	return func(options *circuitBreakerOptions) {
+		options.onStateChange = append(options.onStateChange, callback)
+	}
+}
+
+// DefaultFailurePredicate treats network errors, timeouts and 5xx responses as failures
+func DefaultFailurePredicate(resp *Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp != nil && resp.Raw != nil && resp.StatusCode() >= http.StatusInternalServerError
+}
+
+// CircuitBreaker tracks failures of each host separately, so under ClientBalancer
+// a failing host does not affect others
+type CircuitBreaker struct {
+	options  *circuitBreakerOptions
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+func NewCircuitBreaker(opts ...CircuitBreakerOption) *CircuitBreaker {
+	options := &circuitBreakerOptions{
+		window:           defaultCircuitWindow,
+		windowBuckets:    defaultCircuitWindowBuckets,
+		failureRate:      defaultCircuitFailureRate,
+		minRequests:      defaultCircuitMinRequests,
+		openTimeout:      defaultCircuitOpenTimeout,
+		halfOpenRequests: defaultCircuitHalfOpenRequests,
+		isFailure:        DefaultFailurePredicate,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return &CircuitBreaker{
+		options:  options,
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// Middleware fails fast with CircuitOpenError while circuit of request host is open.
+// Place it after Retry, so each attempt is accounted and a retry may go to another host
+func (b *CircuitBreaker) Middleware() Middleware {
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			host := request.Raw.URL.Host
+			circuit := b.circuit(host)
+			generation, err := circuit.allow(host)
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next.RoundTrip(ctx, request)
+			if err != nil && ctx.Err() != nil {
+				// canceled by caller, host is not at fault
+				circuit.release(generation)
+				return resp, err
+			}
+			circuit.record(host, generation, b.options.isFailure(resp, err))
+			return resp, err
+		})
+	}
+}
+
+// State returns current state of host circuit, host is host[:port] of request url
+func (b *CircuitBreaker) State(host string) CircuitState {
+	circuit := b.circuit(host)
+	circuit.lock.Lock()
+	defer circuit.lock.Unlock()
+	return circuit.state
+}
+
+func (b *CircuitBreaker) circuit(host string) *circuit {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	c, ok := b.circuits[host]
+	if !ok {
+		c = &circuit{
+			options: b.options,
+			buckets: make([]circuitBucket, b.options.windowBuckets),
+		}
+		b.circuits[host] = c
+	}
+	return c
+}
+
+type circuitBucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type circuit struct {
+	options *circuitBreakerOptions
+
+	lock     sync.Mutex
+	state    CircuitState
+	openedAt time.Time
+	// generation is changed on each state change, so results of requests allowed in previous state are ignored
+	generation        uint64
+	buckets           []circuitBucket
+	halfOpenInFlight  int
+	halfOpenSuccesses int
+}
+
+func (c *circuit) allow(host string) (uint64, error) {
+	c.lock.Lock()
+	now := c.options.now()
+	var transition func()
+	switch c.state {
+	case CircuitClosed:
+	case CircuitOpen:
+		openUntil := c.openedAt.Add(c.options.openTimeout)
+		if now.Before(openUntil) {
+			c.lock.Unlock()
+			return 0, CircuitOpenError{Host: host, OpenUntil: openUntil}
+		}
+		transition = c.setState(host, CircuitHalfOpen, now)
+		c.halfOpenInFlight++
+	case CircuitHalfOpen:
+		if c.halfOpenInFlight+c.halfOpenSuccesses >= c.options.halfOpenRequests {
+			c.lock.Unlock()
+			return 0, CircuitOpenError{Host: host}
+		}
+		c.halfOpenInFlight++
+	}
+	generation := c.generation
+	c.lock.Unlock()
+
+	if transition != nil {
+		transition()
+	}
+	return generation, nil
+}
+
+func (c *circuit) release(generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if generation == c.generation && c.state == CircuitHalfOpen {
+		c.halfOpenInFlight--
+	}
+}
+
+func (c *circuit) record(host string, generation uint64, failure bool) {
+	c.lock.Lock()
+	if generation != c.generation {
+		c.lock.Unlock()
+		return
+	}
+
+	now := c.options.now()
+	var transition func()
+	switch c.state {
+	case CircuitClosed:
+		total, failures := c.add(now, failure)
+		if total >= c.options.minRequests && float64(failures) >= c.options.failureRate*float64(total) {
+			transition = c.setState(host, CircuitOpen, now)
+		}
+	case CircuitHalfOpen:
+		c.halfOpenInFlight--
+		if failure {
+			transition = c.setState(host, CircuitOpen, now)
+			break
+		}
+		c.halfOpenSuccesses++
+		if c.halfOpenSuccesses >= c.options.halfOpenRequests {
+			transition = c.setState(host, CircuitClosed, now)
+		}
+	case CircuitOpen:
+	}
+	c.lock.Unlock()
+
+	if transition != nil {
+		transition()
+	}
+}
+
+// add accounts request in the current bucket and returns totals over window
+func (c *circuit) add(now time.Time, failure bool) (int, int) {
+	bucketSize := c.options.window / time.Duration(len(c.buckets))
+	start := now.Truncate(bucketSize)
+	bucket := &c.buckets[int(start.UnixNano()/int64(bucketSize))%len(c.buckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	bucket.total++
+	if failure {
+		bucket.failures++
+	}
+
+	total, failures := 0, 0
+	windowStart := start.Add(-c.options.window)
+	for _, bucket := range c.buckets {
+		if bucket.start.After(windowStart) {
+			total += bucket.total
+			failures += bucket.failures
+		}
+	}
+	return total, failures
+}
+
+// setState must be called under lock, returned func calls callbacks and must be called without lock
+func (c *circuit) setState(host string, state CircuitState, now time.Time) func() {
+	from := c.state
+	c.state = state
+	c.generation++
+	c.halfOpenInFlight = 0
+	c.halfOpenSuccesses = 0
+	switch state {
+	case CircuitOpen:
+		c.openedAt = now
+	case CircuitClosed:
+		clear(c.buckets)
+	case CircuitHalfOpen:
+	}
+
+	callbacks := c.options.onStateChange
+	return func() {
+		for _, callback := range callbacks {
+			callback(host, from, state)
+		}
+	}
+}
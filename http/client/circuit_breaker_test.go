@@ -0,0 +1,171 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/http/client"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type stateChanges struct {
+	lock    sync.Mutex
+	changes []string
+}
+
+func (s *stateChanges) callback(host string, from client.CircuitState, to client.CircuitState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.changes = append(s.changes, from.String()+"->"+to.String())
+}
+
+func (s *stateChanges) get() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.changes...)
+}
+
+func switchableServer(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Bool) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	failing := &atomic.Bool{}
+	failing.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, calls, failing
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls, failing := switchableServer(t)
+	changes := &stateChanges{}
+	breaker := client.NewCircuitBreaker(
+		client.WithFailureRate(0.5, 4, time.Minute),
+		client.WithOpenTimeout(100*time.Millisecond),
+		client.WithStateChangeCallback(changes.callback),
+	)
+	cli := client.New(client.WithMiddlewares(breaker.Middleware()))
+	host := mustHost(t, srv.URL)
+
+	for range 4 {
+		err := cli.Get(srv.URL).DoWithoutResponse(context.Background())
+		require.NoError(err)
+	}
+	require.Equal(client.CircuitOpen, breaker.State(host))
+
+	err := cli.Get(srv.URL).DoWithoutResponse(context.Background())
+	require.ErrorIs(err, client.ErrCircuitOpen)
+	openErr := client.CircuitOpenError{}
+	require.True(errors.As(err, &openErr))
+	require.Equal(host, openErr.Host)
+	require.False(openErr.OpenUntil.IsZero())
+	require.EqualValues(4, calls.Load())
+
+	// failed probe opens circuit again
+	time.Sleep(150 * time.Millisecond)
+	err = cli.Get(srv.URL).DoWithoutResponse(context.Background())
+	require.NoError(err)
+	require.Equal(client.CircuitOpen, breaker.State(host))
+	require.EqualValues(5, calls.Load())
+
+	failing.Store(false)
+	time.Sleep(150 * time.Millisecond)
+	err = cli.Get(srv.URL).DoWithoutResponse(context.Background())
+	require.NoError(err)
+	require.Equal(client.CircuitClosed, breaker.State(host))
+	require.Equal([]string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, changes.get())
+}
+
+func TestCircuitBreaker_FailureRate(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, _, failing := switchableServer(t)
+	breaker := client.NewCircuitBreaker(client.WithFailureRate(0.5, 4, time.Minute))
+	cli := client.New(client.WithMiddlewares(breaker.Middleware()))
+	host := mustHost(t, srv.URL)
+
+	failing.Store(false)
+	for range 5 {
+		require.NoError(cli.Get(srv.URL).DoWithoutResponse(context.Background()))
+	}
+	failing.Store(true)
+	for range 4 {
+		require.NoError(cli.Get(srv.URL).DoWithoutResponse(context.Background()))
+	}
+	require.Equal(client.CircuitClosed, breaker.State(host))
+	require.NoError(cli.Get(srv.URL).DoWithoutResponse(context.Background()))
+	require.Equal(client.CircuitOpen, breaker.State(host))
+}
+
+func TestCircuitBreaker_ShortWindow(t *testing.T) {
+	t.Parallel()
+
+	for _, window := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		srv, _, _ := switchableServer(t)
+		breaker := client.NewCircuitBreaker(client.WithFailureRate(0.5, 1, window))
+		cli := client.New(client.WithMiddlewares(breaker.Middleware()))
+
+		require.NotPanics(t, func() {
+			require.NoError(t, cli.Get(srv.URL).DoWithoutResponse(context.Background()))
+		}, window.String())
+	}
+}
+
+func TestCircuitBreaker_ClientBalancer(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	bad, badCalls, _ := switchableServer(t)
+	good, goodCalls, goodFailing := switchableServer(t)
+	goodFailing.Store(false)
+	breaker := client.NewCircuitBreaker(client.WithFailureRate(0.5, 2, time.Minute))
+	cli := client.NewClientBalancer(
+		[]string{bad.URL, good.URL},
+		client.WithClientOptions(client.WithMiddlewares(
+			client.Retry(client.WithRetryBackoff(0, 0), client.WithRetryPredicate(
+				func(resp *client.Response, err error) bool {
+					return err != nil || resp.StatusCode() >= http.StatusInternalServerError
+				},
+			)),
+			breaker.Middleware(),
+		)),
+	)
+
+	for range 10 {
+		_, status, err := cli.Get("/").DoAndReadBody(context.Background())
+		require.NoError(err)
+		require.Equal(http.StatusOK, status)
+	}
+	require.EqualValues(2, badCalls.Load())
+	require.EqualValues(10, goodCalls.Load())
+	require.Equal(client.CircuitOpen, breaker.State(mustHost(t, bad.URL)))
+	require.Equal(client.CircuitClosed, breaker.State(mustHost(t, good.URL)))
+}
+
+func mustHost(t *testing.T, rawUrl string) string {
+	t.Helper()
+	parsed, err := url.Parse(rawUrl)
+	require.NoError(t, err)
+	return parsed.Host
+}
@@ -132,6 +132,11 @@ func (c *Client) execute(ctx context.Context, builder *RequestBuilder) (*Respons
 		Raw:     request,
 		timeout: builder.timeout,
 	}
+	if builder.nextBaseUrl != nil {
+		rr.retarget = func() error {
+			return retarget(rr.Raw, builder)
+		}
+	}
 
 	if builder.requestBody != nil {
 		buff := acquireBuffer()
@@ -162,6 +167,27 @@ func (c *Client) execute(ctx context.Context, builder *RequestBuilder) (*Respons
 	return resp, nil
 }
 
+// retarget replaces scheme and host of request by the next base url of builder, query is kept
+func retarget(request *http.Request, builder *RequestBuilder) error {
+	baseUrl, err := builder.nextBaseUrl()
+	if err != nil {
+		return err
+	}
+	builder.baseUrl = baseUrl
+	finalUrl, err := builder.GetRequestUrl()
+	if err != nil {
+		return err
+	}
+	parsed, err := url.Parse(finalUrl)
+	if err != nil {
+		return err
+	}
+	parsed.RawQuery = request.URL.RawQuery
+	request.URL = parsed
+	request.Host = parsed.Host
+	return nil
+}
+
 // nolint:bodyclose
 func (c *Client) roundTrip(ctx context.Context, request *Request) (*Response, error) {
 	var (
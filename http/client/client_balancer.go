@@ -63,14 +63,23 @@ func (c *ClientBalancer) Execute(ctx context.Context, builder *RequestBuilder) (
 		return c.Client.execute(ctx, builder)
 	}
 
-	host, err := c.hostManager.Next()
+	host, err := c.nextHost()
 	if err != nil {
-		return nil, errors.WithMessage(err, "host manager next")
+		return nil, err
 	}
-
+	// retries are sent to the next host
+	builder.nextBaseUrl = c.nextHost
 	return c.Client.execute(ctx, builder.BaseUrl(host))
 }
 
+func (c *ClientBalancer) nextHost() (string, error) {
+	host, err := c.hostManager.Next()
+	if err != nil {
+		return "", errors.WithMessage(err, "host manager next")
+	}
+	return host, nil
+}
+
 func (c *ClientBalancer) Upgrade(hosts []string) {
 	hosts = addSchemaToHosts(c.schema, hosts)
 	c.hostManager.Upgrade(hosts)
@@ -3,6 +3,10 @@ package client
 import (
 	"fmt"
 	"net/url"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
 )
 
 // nolint:errname
@@ -15,3 +19,41 @@ type ErrorResponse struct {
 func (e ErrorResponse) Error() string {
 	return fmt.Sprintf("http call error: url=%s status_code=%d, body=%s", e.Url.String(), e.StatusCode, e.Body)
 }
+
+type apiErrorBody struct {
+	apierrors.Problem
+
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// ApiError decodes response body written by apierrors.Error, both as problem details
+// and as {errorCode, errorMessage, details}, response is used as error cause.
+// Returns false if body is not an api error
+func (e ErrorResponse) ApiError() (apierrors.Error, bool) {
+	body := apiErrorBody{}
+	err := json.Unmarshal(e.Body, &body)
+	if err != nil {
+		return apierrors.Error{}, false
+	}
+
+	if body.Type != "" || body.Title != "" {
+		problem := body.Problem
+		if problem.Status == 0 {
+			problem.Status = e.StatusCode
+		}
+		return apierrors.FromProblem(problem, e), true
+	}
+	if body.ErrorCode == 0 && body.ErrorMessage == "" {
+		return apierrors.Error{}, false
+	}
+	return apierrors.New(e.StatusCode, body.ErrorCode, body.ErrorMessage, e).WithDetails(body.Details), true
+}
+
+// AsApiError finds ErrorResponse in err chain and decodes it as apierrors.Error
+func AsApiError(err error) (apierrors.Error, bool) {
+	var errResp ErrorResponse
+	if !errors.As(err, &errResp) {
+		return apierrors.Error{}, false
+	}
+	return errResp.ApiError()
+}
@@ -0,0 +1,46 @@
+package client_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/client"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorResponse_ApiError(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	origin := apierrors.NewBusinessError(http.StatusBadRequest, "invalid request body", nil).
+		WithFieldErrors(map[string]any{"name": "name is required"})
+
+	rec := httptest.NewRecorder()
+	require.NoError(origin.WriteProblem(rec, apierrors.ProblemOptions{Instance: "req-1"}))
+	errResp := client.ErrorResponse{Url: &url.URL{}, StatusCode: rec.Code, Body: rec.Body.Bytes()}
+	apiErr, ok := client.AsApiError(errors.WithMessage(errResp, "call service"))
+	require.True(ok)
+	require.Equal(http.StatusBadRequest, apiErr.HttpStatusCode())
+	require.Equal(origin.ErrorCode, apiErr.ErrorCode)
+	require.Equal(origin.ErrorMessage, apiErr.ErrorMessage)
+	require.Equal(origin.Details, apiErr.Details)
+	require.Equal(origin.Problem(apierrors.ProblemOptions{}), apiErr.Problem(apierrors.ProblemOptions{}))
+
+	rec = httptest.NewRecorder()
+	require.NoError(origin.WriteError(rec))
+	errResp = client.ErrorResponse{Url: &url.URL{}, StatusCode: rec.Code, Body: rec.Body.Bytes()}
+	apiErr, ok = errResp.ApiError()
+	require.True(ok)
+	require.Equal(http.StatusBadRequest, apiErr.HttpStatusCode())
+	require.Equal(origin.ErrorCode, apiErr.ErrorCode)
+	require.Equal(origin.ErrorMessage, apiErr.ErrorMessage)
+	require.Equal(origin.Details, apiErr.Details)
+
+	errResp = client.ErrorResponse{Url: &url.URL{}, StatusCode: http.StatusBadGateway, Body: []byte("bad gateway")}
+	_, ok = errResp.ApiError()
+	require.False(ok)
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// nolint:gochecknoglobals
+var (
+	quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+)
+
+type multipartPart struct {
+	fieldName   string
+	value       string
+	fileName    string
+	contentType string
+	content     io.Reader
+}
+
+// MultipartBody is a multipart/form-data request body with fields and files
+type MultipartBody struct {
+	parts []multipartPart
+}
+
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{}
+}
+
+// Field adds text field
+func (b *MultipartBody) Field(name string, value string) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{fieldName: name, value: value})
+	return b
+}
+
+// File adds file part with content type application/octet-stream
+func (b *MultipartBody) File(fieldName string, fileName string, content io.Reader) *MultipartBody {
+	return b.FileWithContentType(fieldName, fileName, "application/octet-stream", content)
+}
+
+// FileWithContentType adds file part, content is read when request is sent and is not closed
+func (b *MultipartBody) FileWithContentType(
+	fieldName string,
+	fileName string,
+	contentType string,
+	content io.Reader,
+) *MultipartBody {
+	b.parts = append(b.parts, multipartPart{
+		fieldName:   fieldName,
+		fileName:    fileName,
+		contentType: contentType,
+		content:     content,
+	})
+	return b
+}
+
+func (b *MultipartBody) Write(req *http.Request, w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	for _, part := range b.parts {
+		if part.content == nil {
+			err := mw.WriteField(part.fieldName, part.value)
+			if err != nil {
+				return errors.WithMessagef(err, "write field %s", part.fieldName)
+			}
+			continue
+		}
+
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(part.fieldName), quoteEscaper.Replace(part.fileName)))
+		header.Set("Content-Type", part.contentType)
+		writer, err := mw.CreatePart(header)
+		if err != nil {
+			return errors.WithMessagef(err, "create file part %s", part.fieldName)
+		}
+		_, err = io.Copy(writer, part.content)
+		if err != nil {
+			return errors.WithMessagef(err, "copy file %s", part.fileName)
+		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return errors.WithMessage(err, "close multipart writer")
+	}
+	return nil
+}
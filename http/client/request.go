@@ -10,6 +10,8 @@ type Request struct {
 
 	body    []byte
 	timeout time.Duration
+	// retarget sends next attempt to another host, set by ClientBalancer
+	retarget func() error
 }
 
 func (r *Request) Body() []byte {
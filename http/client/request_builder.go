@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -27,6 +28,7 @@ type RequestBuilder struct {
 	queryParams       map[string]any
 	timeout           time.Duration
 	statusCodeToError bool
+	nextBaseUrl       func() (string, error)
 
 	execute func(ctx context.Context, req *RequestBuilder) (*Response, error)
 }
@@ -83,6 +85,34 @@ func (b *RequestBuilder) JsonRequestBody(value any) *RequestBuilder {
 	return b
 }
 
+// FormRequestBody
+// Send values as application/x-www-form-urlencoded
+func (b *RequestBuilder) FormRequestBody(values url.Values) *RequestBuilder {
+	b.requestBody = formRequest{values: values}
+	return b
+}
+
+// MultipartRequestBody
+// Send fields and files as multipart/form-data, see NewMultipartBody
+func (b *RequestBuilder) MultipartRequestBody(body *MultipartBody) *RequestBuilder {
+	b.requestBody = body
+	return b
+}
+
+// XmlRequestBody
+// Send value encoded as application/xml
+func (b *RequestBuilder) XmlRequestBody(value any) *RequestBuilder {
+	b.requestBody = xmlRequest{value: value}
+	return b
+}
+
+// XmlResponseBody
+// If response status code between 200 and 299, unmarshal xml response body to responsePtr
+func (b *RequestBuilder) XmlResponseBody(responsePtr any) *RequestBuilder {
+	b.responseBody = xmlResponse{ptr: responsePtr}
+	return b
+}
+
 // JsonResponseBody
 // If response status code between 200 and 299, unmarshal response body to responsePtr
 func (b *RequestBuilder) JsonResponseBody(responsePtr any) *RequestBuilder {
@@ -118,6 +148,7 @@ func (b *RequestBuilder) Do(ctx context.Context) (*Response, error) {
 
 	if !resp.IsSuccess() && b.statusCodeToError {
 		body, err := resp.BodyCopy()
+		resp.Close()
 		if err != nil {
 			return nil, err
 		}
@@ -139,6 +170,20 @@ func (b *RequestBuilder) DoWithoutResponse(ctx context.Context) error {
 	return nil
 }
 
+// DoStream
+// Return response body as stream without buffering, e.g. for large downloads.
+// If response status code is not between 200 and 299, return ErrorResponse as error.
+// Caller must close returned reader. Timeout also limits reading of the body, use Timeout(0) to disable it
+func (b *RequestBuilder) DoStream(ctx context.Context) (io.ReadCloser, error) {
+	b.responseBody = nil
+	b.statusCodeToError = true
+	resp, err := b.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.BodyReader()
+}
+
 func (b *RequestBuilder) DoAndReadBody(ctx context.Context) ([]byte, int, error) {
 	resp, err := b.Do(ctx)
 	if err != nil {
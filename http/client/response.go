@@ -5,6 +5,12 @@ import (
 	"context"
 	"io"
 	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrBodyStreamed = errors.New("response body is already streamed")
 )
 
 type Response struct {
@@ -14,6 +20,7 @@ type Response struct {
 	body   []byte
 	err    error
 	buff   *bytes.Buffer
+	closed bool
 }
 
 type ReadingResponseMetricHook struct{}
@@ -47,10 +54,7 @@ func (r *Response) Body() ([]byte, error) {
 		return nil, err
 	}
 
-	hook, ok := r.Raw.Request.Context().Value(ReadingResponseMetricHookKey).(func())
-	if ok {
-		hook()
-	}
+	r.readingCompleted()
 
 	r.body = r.buff.Bytes()
 	return r.body, nil
@@ -60,6 +64,10 @@ func (r *Response) Body() ([]byte, error) {
 // Release all resources associated with Response (buffer, tcp connection, context)
 // After call, bytes slice returned by Body can not be used
 func (r *Response) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
 	if r.cancel != nil {
 		r.cancel()
 		r.cancel = nil
@@ -71,6 +79,46 @@ func (r *Response) Close() {
 	releaseBuffer(r.buff)
 }
 
+// BodyReader
+// Return response body as stream, it is not buffered, so large bodies can be read with constant memory.
+// Closing returned reader closes Response, Body can not be used after call
+func (r *Response) BodyReader() (io.ReadCloser, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.body != nil {
+		return nil, errors.New("response body is already read")
+	}
+	r.err = ErrBodyStreamed
+	return &bodyReader{resp: r}, nil
+}
+
+func (r *Response) readingCompleted() {
+	hook, ok := r.Raw.Request.Context().Value(ReadingResponseMetricHookKey).(func())
+	if ok {
+		hook()
+	}
+}
+
+type bodyReader struct {
+	resp *Response
+	eof  bool
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.resp.Raw.Body.Read(p)
+	if errors.Is(err, io.EOF) && !b.eof {
+		b.eof = true
+		b.resp.readingCompleted()
+	}
+	return n, err
+}
+
+func (b *bodyReader) Close() error {
+	b.resp.Close()
+	return nil
+}
+
 func (r *Response) IsSuccess() bool {
 	return r.StatusCode() >= http.StatusOK && r.StatusCode() < http.StatusMultipleChoices
 }
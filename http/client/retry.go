@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	defaultRetryMaxAttempts   = 3
+	defaultRetryMinDelay      = 100 * time.Millisecond
+	defaultRetryMaxDelay      = 5 * time.Second
+	defaultRetryMaxRetryAfter = 30 * time.Second
+)
+
+// nolint:gochecknoglobals
+var (
+	idempotentMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete,
+	}
+	retryableStatusCodes = []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+)
+
+// RetryPredicate reports whether attempt finished with resp or err should be retried,
+// resp may be nil if err is not nil
+type RetryPredicate func(resp *Response, err error) bool
+
+type retryOptions struct {
+	maxAttempts   int
+	minDelay      time.Duration
+	maxDelay      time.Duration
+	maxRetryAfter time.Duration
+	allMethods    bool
+	predicate     RetryPredicate
+}
+
+type RetryOption func(options *retryOptions)
+
+// WithMaxAttempts sets max count of attempts including the first one, default 3
+func WithMaxAttempts(maxAttempts int) RetryOption {
+	return func(options *retryOptions) {
+		options.maxAttempts = max(maxAttempts, 1)
+	}
+}
+
+// WithRetryBackoff sets bounds of exponential backoff with full jitter between attempts, default 100ms and 5s
+func WithRetryBackoff(minDelay time.Duration, maxDelay time.Duration) RetryOption {
+	return func(options *retryOptions) {
+		options.minDelay = minDelay
+		options.maxDelay = max(minDelay, maxDelay)
+	}
+}
+
+// WithMaxRetryAfter sets max delay requested by Retry-After header which is waited, default 30s.
+// Response with greater Retry-After is returned without retry
+func WithMaxRetryAfter(maxRetryAfter time.Duration) RetryOption {
+	return func(options *retryOptions) {
+		options.maxRetryAfter = maxRetryAfter
+	}
+}
+
+// WithRetryAllMethods enables retry of non idempotent methods (POST, PATCH).
+// Without it such requests are retried only if Idempotency-Key header is set
+func WithRetryAllMethods() RetryOption {
+	return func(options *retryOptions) {
+		options.allMethods = true
+	}
+}
+
+// WithRetryPredicate replaces DefaultRetryPredicate
+func WithRetryPredicate(predicate RetryPredicate) RetryOption {
+	return func(options *retryOptions) {
+		options.predicate = predicate
+	}
+}
+
+// DefaultRetryPredicate retries network errors and per attempt timeouts
+// and responses with status codes 429, 502, 503 and 504
+func DefaultRetryPredicate(resp *Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp != nil && resp.Raw != nil && slices.Contains(retryableStatusCodes, resp.StatusCode())
+}
+
+// Retry repeats request while predicate allows it, waiting jittered exponential backoff or Retry-After
+// between attempts. Only idempotent methods are retried by default.
+// Under ClientBalancer each retry is sent to the next host.
+// Discarded responses are closed
+func Retry(opts ...RetryOption) Middleware {
+	options := &retryOptions{
+		maxAttempts:   defaultRetryMaxAttempts,
+		minDelay:      defaultRetryMinDelay,
+		maxDelay:      defaultRetryMaxDelay,
+		maxRetryAfter: defaultRetryMaxRetryAfter,
+		predicate:     DefaultRetryPredicate,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return func(next RoundTripper) RoundTripper {
+		return RoundTripperFunc(func(ctx context.Context, request *Request) (*Response, error) {
+			if !options.retryable(request) {
+				return next.RoundTrip(ctx, request)
+			}
+
+			for attempt := 1; ; attempt++ {
+				resp, err := next.RoundTrip(ctx, request)
+				if attempt >= options.maxAttempts || !options.predicate(resp, err) {
+					return resp, err
+				}
+
+				delay, ok := options.delay(attempt, resp)
+				if !ok {
+					return resp, err
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					if err != nil {
+						return resp, errors.WithMessagef(err, "retry interrupted after %d attempts", attempt)
+					}
+					return resp, nil
+				case <-timer.C:
+				}
+
+				if resp != nil {
+					resp.Close()
+				}
+				if request.retarget != nil {
+					err := request.retarget()
+					if err != nil {
+						return nil, errors.WithMessage(err, "select next host")
+					}
+				}
+			}
+		})
+	}
+}
+
+func (o *retryOptions) retryable(request *Request) bool {
+	if o.allMethods || slices.Contains(idempotentMethods, request.Raw.Method) {
+		return true
+	}
+	return request.Raw.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// delay returns delay before next attempt, false if Retry-After exceeds maxRetryAfter
+func (o *retryOptions) delay(attempt int, resp *Response) (time.Duration, bool) {
+	delay := o.backoff(attempt)
+	if resp == nil || resp.Raw == nil {
+		return delay, true
+	}
+	retryAfter, ok := parseRetryAfter(resp.Raw.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return delay, true
+	}
+	if retryAfter > o.maxRetryAfter {
+		return 0, false
+	}
+	return max(delay, retryAfter), true
+}
+
+// nolint:gosec
+func (o *retryOptions) backoff(attempt int) time.Duration {
+	delay := o.minDelay
+	for i := 1; i < attempt && delay < o.maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.maxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return rand.N(delay)
+}
+
+// parseRetryAfter parses Retry-After as delay in seconds or as http date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
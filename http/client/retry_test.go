@@ -0,0 +1,163 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/http/client"
+	"github.com/stretchr/testify/require"
+)
+
+func failingServer(t *testing.T, failures int32, statusCode int, headers map[string]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	calls := &atomic.Int32{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+			w.WriteHeader(statusCode)
+			return
+		}
+		_, _ = w.Write([]byte(r.URL.RawQuery))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, calls
+}
+
+func newRetryClient(opts ...client.RetryOption) *client.Client {
+	opts = append([]client.RetryOption{client.WithRetryBackoff(time.Millisecond, 5*time.Millisecond)}, opts...)
+	return client.New(client.WithMiddlewares(client.Retry(opts...)))
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 2, http.StatusServiceUnavailable, nil)
+	body, status, err := newRetryClient().Get(srv.URL).
+		QueryParams(map[string]any{"a": 1}).
+		DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.Equal("a=1", string(body))
+	require.EqualValues(3, calls.Load())
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 5, http.StatusBadGateway, nil)
+	_, status, err := newRetryClient(client.WithMaxAttempts(2)).Get(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusBadGateway, status)
+	require.EqualValues(2, calls.Load())
+}
+
+func TestRetry_NotRetryableStatus(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 1, http.StatusInternalServerError, nil)
+	_, status, err := newRetryClient().Get(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusInternalServerError, status)
+	require.EqualValues(1, calls.Load())
+
+	srv, calls = failingServer(t, 1, http.StatusInternalServerError, nil)
+	predicate := func(resp *client.Response, err error) bool {
+		return err != nil || resp.StatusCode() >= http.StatusInternalServerError
+	}
+	_, status, err = newRetryClient(client.WithRetryPredicate(predicate)).Get(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.EqualValues(2, calls.Load())
+}
+
+func TestRetry_NonIdempotentMethod(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	_, status, err := newRetryClient().Post(srv.URL).RequestBody([]byte("data")).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusServiceUnavailable, status)
+	require.EqualValues(1, calls.Load())
+
+	srv, calls = failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	_, status, err = newRetryClient().Post(srv.URL).
+		Header(client.IdempotencyKeyHeader, "key").
+		RequestBody([]byte("data")).
+		DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.EqualValues(2, calls.Load())
+
+	srv, calls = failingServer(t, 1, http.StatusServiceUnavailable, nil)
+	_, status, err = newRetryClient(client.WithRetryAllMethods()).Patch(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.EqualValues(2, calls.Load())
+}
+
+func TestRetry_RetryAfter(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 1, http.StatusTooManyRequests, map[string]string{"Retry-After": "1"})
+	start := time.Now()
+	_, status, err := newRetryClient().Get(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusOK, status)
+	require.EqualValues(2, calls.Load())
+	require.GreaterOrEqual(time.Since(start), time.Second)
+
+	srv, calls = failingServer(t, 1, http.StatusTooManyRequests, map[string]string{"Retry-After": "120"})
+	_, status, err = newRetryClient().Get(srv.URL).DoAndReadBody(context.Background())
+	require.NoError(err)
+	require.Equal(http.StatusTooManyRequests, status)
+	require.EqualValues(1, calls.Load())
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	srv, calls := failingServer(t, 5, http.StatusServiceUnavailable, map[string]string{"Retry-After": "10"})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, status, err := newRetryClient().Get(srv.URL).DoAndReadBody(ctx)
+	require.NoError(err)
+	require.Equal(http.StatusServiceUnavailable, status)
+	require.EqualValues(1, calls.Load())
+}
+
+func TestRetry_ClientBalancer(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	srv, calls := failingServer(t, 0, 0, nil)
+	cli := client.NewClientBalancer(
+		[]string{down.URL, srv.URL},
+		client.WithClientOptions(client.WithMiddlewares(
+			client.Retry(client.WithRetryBackoff(time.Millisecond, time.Millisecond)),
+		)),
+	)
+
+	for range 4 {
+		body, status, err := cli.Get("/path").
+			QueryParams(map[string]any{"q": "v"}).
+			DoAndReadBody(context.Background())
+		require.NoError(err)
+		require.Equal(http.StatusOK, status)
+		require.Equal("q=v", string(body))
+	}
+	require.EqualValues(4, calls.Load())
+}
@@ -57,6 +57,11 @@ func bindDataRecursive(valuesMap map[string][]string, dest any, tag, prefix stri
 
 		key := prefix + fi.fieldName
 
+		// Files are bound by bindMultipart
+		if isFileType(fi.fieldType) {
+			continue
+		}
+
 		// Handle anonymous (embedded) structs
 		if fi.anonymous {
 			if fi.isPtr {
@@ -109,53 +114,66 @@ func bindDataRecursive(valuesMap map[string][]string, dest any, tag, prefix stri
 			continue
 		}
 
-		// Try unmarshaling slice values
-		ok, err := unmarshalInputsToField(values, fieldValue)
+		err = setFieldValues(values, fieldValue)
 		if err != nil {
-			return errors.WithMessagef(err, "unmarshal slice field %q", key)
-		}
-		if ok {
-			continue
+			return errors.WithMessagef(err, "set field %q", key)
 		}
+	}
 
-		// Try unmarshaling single value
-		ok, err = unmarshalInputToField(values[0], fieldValue)
-		if err != nil {
-			return errors.WithMessagef(err, "unmarshal field %q", key)
-		}
-		if ok {
-			continue
-		}
+	return nil
+}
 
-		// Dereference pointers
-		fieldKind := fieldValue.Kind()
-		if fieldKind == reflect.Ptr {
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
-			}
-			fieldValue = fieldValue.Elem()
-		}
+// setFieldValues assigns non-empty values to field using unmarshalers or conversion by field kind.
+// nolint:cyclop
+func setFieldValues(values []string, fieldValue reflect.Value) error {
+	// Try unmarshaling slice values
+	ok, err := unmarshalInputsToField(values, fieldValue)
+	if err != nil {
+		return errors.WithMessage(err, "unmarshal slice field")
+	}
+	if ok {
+		return nil
+	}
 
-		// Handle slice creation and assignment
-		if fi.isSlice {
-			numElems := len(values)
-			slice := reflect.MakeSlice(fi.fieldType, numElems, numElems)
-			for i := range values {
-				err := setWithProperType(values[i], slice.Index(i))
-				if err != nil {
-					return errors.WithMessagef(err, "set slice element %d of field %q", i, key)
-				}
-			}
-			fieldValue.Set(slice)
-			continue
+	// Try unmarshaling single value
+	ok, err = unmarshalInputToField(values[0], fieldValue)
+	if err != nil {
+		return errors.WithMessage(err, "unmarshal field")
+	}
+	if ok {
+		return nil
+	}
+
+	// Dereference pointers
+	fieldKind := fieldValue.Kind()
+	if fieldKind == reflect.Ptr {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
 		}
+		fieldValue = fieldValue.Elem()
+	}
 
-		// Handle single value assignment
-		err = setWithProperType(values[0], fieldValue)
-		if err != nil {
-			return errors.WithMessagef(err, "set field %q", key)
+	// Handle slice creation and assignment
+	if fieldValue.Kind() == reflect.Slice {
+		numElems := len(values)
+		slice := reflect.MakeSlice(fieldValue.Type(), numElems, numElems)
+		for i := range values {
+			ok, err := unmarshalInputToField(values[i], slice.Index(i))
+			if err != nil {
+				return errors.WithMessagef(err, "unmarshal slice element %d", i)
+			}
+			if ok {
+				continue
+			}
+			err = setWithProperType(values[i], slice.Index(i))
+			if err != nil {
+				return errors.WithMessagef(err, "set slice element %d", i)
+			}
 		}
+		fieldValue.Set(slice)
+		return nil
 	}
 
-	return nil
+	// Handle single value assignment
+	return setWithProperType(values[0], fieldValue)
 }
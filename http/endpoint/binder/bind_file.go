@@ -0,0 +1,241 @@
+package binder
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/types"
+	"github.com/pkg/errors"
+)
+
+// nolint:gochecknoglobals
+var (
+	fileHeaderType     = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderListType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	fileStreamType     = reflect.TypeOf(types.FileStream{})
+	fileStreamPtrType  = reflect.TypeOf((*types.FileStream)(nil))
+)
+
+/**
+ * bindMultipart binds multipart/form-data request to dest.
+ *
+ * Form values are bound like other form values. File parts are bound to top level fields of types:
+ *   - *multipart.FileHeader and []*multipart.FileHeader: the whole form is parsed,
+ *     files larger than multipart memory threshold are stored in temporary files;
+ *   - types.FileStream or *types.FileStream: form is read part by part and
+ *     the file is read directly from request body by handler, it must be the last part of the form.
+ *
+ * Size of each file may be limited by maxSize tag, e.g. `form:"avatar" maxSize:"5MB"`,
+ * request with larger file fails with 413.
+ */
+func (b *RequestBinder) bindMultipart(r *http.Request, dest reflect.Value) error {
+	structType := dest.Type().Elem()
+	if structType.Kind() != reflect.Struct {
+		return nil
+	}
+	info := getStructInfo(structType, FormTag)
+	for _, fi := range info.fields {
+		if fi.maxSizeErr != nil {
+			return errors.WithMessagef(fi.maxSizeErr, "parse maxSize of field %q", fi.fieldName)
+		}
+	}
+
+	streamField, hasStream := findFileStreamField(info)
+	if hasStream {
+		return b.bindMultipartStream(r, dest, streamField)
+	}
+
+	err := r.ParseMultipartForm(b.multipartMemory)
+	if err != nil {
+		return multipartError(err)
+	}
+
+	err = BindData(r.MultipartForm.Value, dest.Interface(), FormTag)
+	if err != nil {
+		return apierrors.NewBusinessError(http.StatusBadRequest, "invalid request body", err)
+	}
+
+	v := dest.Elem()
+	for _, fi := range info.fields {
+		files := r.MultipartForm.File[fi.fieldName]
+		if len(files) == 0 {
+			continue
+		}
+		switch fi.fieldType {
+		case fileHeaderType:
+			err = checkFileSize(fi, files[0].Size)
+			if err != nil {
+				return err
+			}
+			v.Field(fi.index).Set(reflect.ValueOf(files[0]))
+		case fileHeaderListType:
+			for _, file := range files {
+				err = checkFileSize(fi, file.Size)
+				if err != nil {
+					return err
+				}
+			}
+			v.Field(fi.index).Set(reflect.ValueOf(files))
+		}
+	}
+	return nil
+}
+
+func (b *RequestBinder) bindMultipartStream(r *http.Request, dest reflect.Value, streamField fieldInfo) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return apierrors.NewBusinessError(http.StatusBadRequest, "invalid multipart form", err)
+	}
+
+	values := make(map[string][]string)
+	valuesSize := int64(0)
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return multipartError(err)
+		}
+
+		name := part.FormName()
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, b.multipartMemory-valuesSize+1))
+			if err != nil {
+				return multipartError(err)
+			}
+			valuesSize += int64(len(value))
+			if valuesSize > b.multipartMemory {
+				return apierrors.NewRequestTooLargeError("multipart form values are too large")
+			}
+			values[name] = append(values[name], string(value))
+			continue
+		}
+		if name != streamField.fieldName {
+			continue
+		}
+
+		stream := types.FileStream{
+			Reader:      newFileSizeLimitReader(part, streamField),
+			FieldName:   name,
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Header:      part.Header,
+		}
+		field := dest.Elem().Field(streamField.index)
+		if streamField.fieldType == fileStreamPtrType {
+			field.Set(reflect.ValueOf(&stream))
+		} else {
+			field.Set(reflect.ValueOf(stream))
+		}
+		break
+	}
+
+	err = BindData(values, dest.Interface(), FormTag)
+	if err != nil {
+		return apierrors.NewBusinessError(http.StatusBadRequest, "invalid request body", err)
+	}
+	return nil
+}
+
+func isFileType(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeaderListType || t == fileStreamType || t == fileStreamPtrType
+}
+
+func findFileStreamField(info *structInfo) (fieldInfo, bool) {
+	for _, fi := range info.fields {
+		if fi.fieldType == fileStreamType || fi.fieldType == fileStreamPtrType {
+			return fi, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+func checkFileSize(fi fieldInfo, size int64) error {
+	if fi.maxSize > 0 && size > fi.maxSize {
+		return fileTooLargeError(fi)
+	}
+	return nil
+}
+
+func fileTooLargeError(fi fieldInfo) error {
+	return apierrors.NewRequestTooLargeError("file is too large").
+		WithFieldErrors(map[string]any{
+			fi.fieldName: fmt.Sprintf("file size must be at most %d bytes", fi.maxSize),
+		})
+}
+
+func multipartError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) || errors.Is(err, multipart.ErrMessageTooLarge) {
+		return apierrors.NewRequestTooLargeError("request body is too large")
+	}
+	return apierrors.NewBusinessError(http.StatusBadRequest, "invalid multipart form", err)
+}
+
+// fileSizeLimitReader fails with 413 error if file is larger than maxSize of field
+type fileSizeLimitReader struct {
+	reader    io.Reader
+	field     fieldInfo
+	remaining int64
+}
+
+func newFileSizeLimitReader(reader io.Reader, field fieldInfo) io.Reader {
+	if field.maxSize <= 0 {
+		return reader
+	}
+	return &fileSizeLimitReader{
+		reader:    reader,
+		field:     field,
+		remaining: field.maxSize,
+	}
+}
+
+func (r *fileSizeLimitReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, fileTooLargeError(r.field)
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), fileTooLargeError(r.field)
+	}
+	return n, err
+}
+
+// parseSize parses size with optional binary unit suffix, e.g. 512, 10KB, 5MB, 1GB
+func parseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+	multiplier := int64(1)
+	for _, unit := range units {
+		number, ok := strings.CutSuffix(value, unit.suffix)
+		if ok {
+			value = strings.TrimSpace(number)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, errors.Errorf("invalid size %q", value)
+	}
+	return size * multiplier, nil
+}
@@ -0,0 +1,141 @@
+package binder
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FieldError is an error of binding value from named request source, e.g. header
+type FieldError struct {
+	Name string
+	Err  error
+}
+
+func (e FieldError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+/**
+ * BindHeaders binds request headers to fields with `header` tag, e.g. `header:"X-Tenant-Id"`.
+ * Slice fields get all header values, comma separated values are split.
+ * Fields without tag are not bound. Embedded structs are supported.
+ *
+ * Returns FieldError with header name if value can't be assigned.
+ */
+func BindHeaders(r *http.Request, dest any) error {
+	return bindSource(dest, HeaderTag, func(name string, isSlice bool) []string {
+		values := r.Header.Values(name)
+		if !isSlice {
+			return values
+		}
+		result := make([]string, 0, len(values))
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				part = strings.TrimSpace(part)
+				if part != "" {
+					result = append(result, part)
+				}
+			}
+		}
+		return result
+	})
+}
+
+/**
+ * BindCookies binds request cookies to fields with `cookie` tag, e.g. `cookie:"session"`.
+ * Slice fields get values of all cookies with the name.
+ * Fields without tag are not bound. Embedded structs are supported.
+ *
+ * Returns FieldError with cookie name if value can't be assigned.
+ */
+func BindCookies(r *http.Request, dest any) error {
+	cookies := r.Cookies()
+	return bindSource(dest, CookieTag, func(name string, _ bool) []string {
+		values := make([]string, 0)
+		for _, cookie := range cookies {
+			if cookie.Name == name {
+				values = append(values, cookie.Value)
+			}
+		}
+		return values
+	})
+}
+
+func bindSource(dest any, tag string, getValues func(name string, isSlice bool) []string) error {
+	v, err := getStructValue(dest)
+	if err != nil {
+		return err
+	}
+	return bindSourceFields(v, tag, getValues)
+}
+
+func bindSourceFields(v reflect.Value, tag string, getValues func(name string, isSlice bool) []string) error {
+	info := getStructInfo(v.Type(), tag)
+	for _, fi := range info.fields {
+		fieldValue := v.Field(fi.index)
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		if fi.anonymous {
+			if fi.isPtr {
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct {
+				err := bindSourceFields(fieldValue, tag, getValues)
+				if err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		isSlice := fi.isSlice || (fi.isPtr && fi.fieldType.Elem().Kind() == reflect.Slice)
+		values := getValues(fi.fieldName, isSlice)
+		if len(values) == 0 {
+			continue
+		}
+		err := setFieldValues(values, fieldValue)
+		if err != nil {
+			return FieldError{Name: fi.fieldName, Err: errors.WithMessagef(err, "set %s", tag)}
+		}
+	}
+	return nil
+}
+
+// sourceNames returns names of header and cookie fields by lowerCamelCase path of field,
+// which is used as a key of validation details
+func sourceNames(t reflect.Type) map[string]string {
+	names := make(map[string]string)
+	collectSourceNames(t, "", HeaderTag, names)
+	collectSourceNames(t, "", CookieTag, names)
+	return names
+}
+
+func collectSourceNames(t reflect.Type, prefix string, tag string, names map[string]string) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for _, fi := range getStructInfo(t, tag).fields {
+		field := t.Field(fi.index)
+		key := prefix + formatDetailKey(field.Name)
+		if fi.anonymous {
+			collectSourceNames(field.Type, key+".", tag, names)
+			continue
+		}
+		names[key] = fi.fieldName
+	}
+}
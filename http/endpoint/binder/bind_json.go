@@ -0,0 +1,382 @@
+package binder
+
+import (
+	"bytes"
+	"encoding"
+	stdjson "encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
+)
+
+const (
+	jsonRootPath = "$"
+)
+
+// nolint:gochecknoglobals
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*stdjson.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonOffsetRegexp    = regexp.MustCompile(`error found in #(\d+) byte`)
+
+	jsonFieldsCache sync.Map // reflect.Type -> map[string]reflect.Type
+)
+
+// JsonError describes location of invalid json in request body
+type JsonError struct {
+	// Path is a JSONPath of invalid value, e.g. $.items[2].price
+	Path string
+	// Offset is a byte offset in request body
+	Offset int64
+	Reason string
+}
+
+func (e JsonError) Error() string {
+	return fmt.Sprintf("%s at %s (offset %d)", e.Reason, e.Path, e.Offset)
+}
+
+/**
+ * bindJson decodes json body into dest.
+ *
+ * In strict mode body is checked before decoding: unknown fields and duplicate keys are rejected.
+ * Decode errors are returned as 400 with json path and byte offset in details,
+ * body exceeding MaxRequestBodySize is returned as 413.
+ */
+func (b *RequestBinder) bindJson(r *http.Request, dest reflect.Value) error {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return readBodyError(err)
+	}
+
+	if b.strictJson {
+		err = checkJson(data, dest.Type(), true)
+		if err != nil {
+			return jsonBindError(err)
+		}
+	}
+
+	err = json.Unmarshal(data, dest.Interface())
+	if err == nil {
+		return nil
+	}
+
+	locateErr := checkJson(data, dest.Type(), false)
+	if locateErr != nil {
+		return jsonBindError(locateErr)
+	}
+	jsonErr := JsonError{Path: jsonRootPath, Offset: -1, Reason: err.Error()}
+	match := jsonOffsetRegexp.FindStringSubmatch(err.Error())
+	if match != nil {
+		jsonErr.Offset, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+	return jsonBindError(errors.WithMessage(jsonErr, "decode json"))
+}
+
+func jsonBindError(err error) error {
+	apiErr := apierrors.NewBusinessError(http.StatusBadRequest, "invalid request body", err)
+	var jsonErr JsonError
+	if errors.As(err, &jsonErr) {
+		details := map[string]any{
+			"path":   jsonErr.Path,
+			"reason": jsonErr.Reason,
+		}
+		if jsonErr.Offset >= 0 {
+			details["offset"] = jsonErr.Offset
+		}
+		apiErr = apiErr.WithDetails(details)
+	}
+	return apiErr
+}
+
+func readBodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return apierrors.NewRequestTooLargeError(
+			fmt.Sprintf("request body is too large, limit is %d bytes", maxBytesErr.Limit),
+		)
+	}
+	return apierrors.NewBusinessError(http.StatusBadRequest, "read request body", err)
+}
+
+// checkJson walks json tokens and checks them against type t.
+// Type mismatches are always reported, unknown fields and duplicate keys only in strict mode
+func checkJson(data []byte, t reflect.Type, strict bool) error {
+	decoder := stdjson.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	walker := jsonWalker{data: data, decoder: decoder, strict: strict}
+	err := walker.value(t, jsonRootPath)
+	if err != nil {
+		return err
+	}
+	offset := walker.tokenOffset()
+	_, err = decoder.Token()
+	if !errors.Is(err, io.EOF) {
+		return JsonError{Path: jsonRootPath, Offset: offset, Reason: "unexpected data after top-level value"}
+	}
+	return nil
+}
+
+type jsonWalker struct {
+	data    []byte
+	decoder *stdjson.Decoder
+	strict  bool
+}
+
+// tokenOffset returns offset of the next token skipping whitespaces and separators
+func (w jsonWalker) tokenOffset() int64 {
+	offset := w.decoder.InputOffset()
+	for offset < int64(len(w.data)) && strings.IndexByte(" \t\r\n:,", w.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// nolint:cyclop,funlen,gocognit
+func (w jsonWalker) value(t reflect.Type, path string) error {
+	offset := w.tokenOffset()
+	token, err := w.decoder.Token()
+	if err != nil {
+		var syntaxErr *stdjson.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset
+		}
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return JsonError{Path: path, Offset: offset, Reason: err.Error()}
+	}
+
+	t = jsonCheckedType(t)
+	if isTextUnmarshaler(t) {
+		return checkText(t, token, path, offset)
+	}
+	mismatch := func(got string) error {
+		return JsonError{Path: path, Offset: offset, Reason: fmt.Sprintf("expected %s, got %s", t.Kind(), got)}
+	}
+
+	switch token := token.(type) {
+	case stdjson.Delim:
+		if token == '[' {
+			var elemType reflect.Type
+			if t != nil {
+				switch t.Kind() {
+				case reflect.Slice, reflect.Array:
+					elemType = t.Elem()
+				case reflect.Interface:
+				default:
+					return mismatch("array")
+				}
+			}
+			for i := 0; w.decoder.More(); i++ {
+				err := w.value(elemType, path+"["+strconv.Itoa(i)+"]")
+				if err != nil {
+					return err
+				}
+			}
+			_, err := w.decoder.Token()
+			if err != nil {
+				return JsonError{Path: path, Offset: w.decoder.InputOffset(), Reason: err.Error()}
+			}
+			return nil
+		}
+
+		var (
+			fields   map[string]reflect.Type
+			elemType reflect.Type
+		)
+		if t != nil {
+			switch t.Kind() {
+			case reflect.Struct:
+				fields = jsonFields(t)
+			case reflect.Map:
+				elemType = t.Elem()
+			case reflect.Interface:
+			default:
+				return mismatch("object")
+			}
+		}
+		seen := make(map[string]struct{})
+		for w.decoder.More() {
+			keyOffset := w.tokenOffset()
+			keyToken, err := w.decoder.Token()
+			if err != nil {
+				var syntaxErr *stdjson.SyntaxError
+				if errors.As(err, &syntaxErr) {
+					keyOffset = syntaxErr.Offset
+				}
+				return JsonError{Path: path, Offset: keyOffset, Reason: err.Error()}
+			}
+			key, _ := keyToken.(string)
+			keyPath := path + "." + key
+
+			if w.strict {
+				// struct fields are matched case-insensitively, so "name" and "Name" are the same key
+				seenKey := key
+				if fields != nil {
+					seenKey = strings.ToLower(key)
+				}
+				_, duplicate := seen[seenKey]
+				if duplicate {
+					return JsonError{Path: keyPath, Offset: keyOffset, Reason: "duplicate key"}
+				}
+				seen[seenKey] = struct{}{}
+			}
+
+			valueType := elemType
+			if fields != nil {
+				fieldType, ok := fields[strings.ToLower(key)]
+				if !ok && w.strict {
+					return JsonError{Path: keyPath, Offset: keyOffset, Reason: "unknown field"}
+				}
+				valueType = fieldType
+			}
+			err = w.value(valueType, keyPath)
+			if err != nil {
+				return err
+			}
+		}
+		_, err := w.decoder.Token()
+		if err != nil {
+			return JsonError{Path: path, Offset: w.decoder.InputOffset(), Reason: err.Error()}
+		}
+		return nil
+	case string:
+		if t != nil && t.Kind() != reflect.String && t.Kind() != reflect.Interface &&
+			(t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8) {
+			return mismatch("string")
+		}
+	case stdjson.Number:
+		if t != nil && !isNumberKind(t.Kind()) && t.Kind() != reflect.Interface {
+			return mismatch("number")
+		}
+	case bool:
+		if t != nil && t.Kind() != reflect.Bool && t.Kind() != reflect.Interface {
+			return mismatch("bool")
+		}
+	}
+	return nil
+}
+
+// jsonCheckedType dereferences pointers, returns nil for types with custom decoding
+func jsonCheckedType(t reflect.Type) reflect.Type {
+	for t != nil {
+		if t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) ||
+			t.PkgPath() == "time" && t.Name() == "Time" {
+			return nil
+		}
+		if t.Kind() != reflect.Ptr {
+			return t
+		}
+		t = t.Elem()
+	}
+	return nil
+}
+
+// isTextUnmarshaler reports whether t is decoded from json string by encoding.TextUnmarshaler, e.g. uuid.UUID
+func isTextUnmarshaler(t reflect.Type) bool {
+	return t != nil && t.Kind() != reflect.Interface &&
+		(t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType))
+}
+
+// checkText decodes string token by encoding.TextUnmarshaler of type t to report invalid value at its path
+func checkText(t reflect.Type, token stdjson.Token, path string, offset int64) error {
+	got := ""
+	switch token := token.(type) {
+	case nil:
+		return nil
+	case string:
+		unmarshaler, _ := reflect.New(t).Interface().(encoding.TextUnmarshaler)
+		err := unmarshaler.UnmarshalText([]byte(token))
+		if err != nil {
+			return JsonError{Path: path, Offset: offset, Reason: err.Error()}
+		}
+		return nil
+	case stdjson.Delim:
+		got = "object"
+		if token == '[' {
+			got = "array"
+		}
+	case stdjson.Number:
+		got = "number"
+	case bool:
+		got = "bool"
+	}
+	return JsonError{Path: path, Offset: offset, Reason: "expected string, got " + got}
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind { // nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonFields returns types of struct fields by lower cased json name,
+// names follow github.com/Falokut/go-kit/json naming: json tag or field name with lower first letter
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	cached, ok := jsonFieldsCache.Load(t)
+	if ok {
+		fields, _ := cached.(map[string]reflect.Type)
+		return fields
+	}
+
+	fields := make(map[string]reflect.Type)
+	collectJsonFields(t, fields)
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectJsonFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, tagged := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && !tagged {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectJsonFields(embedded, fields)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		key := strings.ToLower(name)
+		_, exists := fields[key]
+		if !exists {
+			fields[key] = field.Type
+		}
+	}
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if ok {
+		name, _, _ := strings.Cut(tag, ",")
+		if name != "" {
+			return name, true
+		}
+	}
+	runes := []rune(field.Name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes), false
+}
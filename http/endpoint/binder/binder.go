@@ -12,7 +12,6 @@ import (
 	"unicode"
 
 	"github.com/Falokut/go-kit/http/apierrors"
-	"github.com/Falokut/go-kit/json"
 	"github.com/pkg/errors"
 )
 
@@ -21,19 +20,26 @@ type Validator interface {
 }
 
 type RequestBinder struct {
-	validator Validator
+	validator       Validator
+	multipartMemory int64
+	strictJson      bool
 }
 
-func NewRequestBinder(validator Validator) *RequestBinder {
-	return &RequestBinder{
-		validator: validator,
+func NewRequestBinder(validator Validator, opts ...Option) *RequestBinder {
+	b := &RequestBinder{
+		validator:       validator,
+		multipartMemory: defaultMultipartMemory,
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
 }
 
 /**
  * Bind takes the incoming HTTP request, extracts the necessary data based on the request method,
  * and binds it to the provided destination type. It handles query parameters, path parameters,
- * and request body binding. It also performs validation on the bound data using the validator.
+ * headers, cookies and request body binding. It also performs validation on the bound data using the validator.
  *
  * Parameters:
  *   - ctx: the context of the request
@@ -64,6 +70,16 @@ func (b *RequestBinder) Bind(
 			apierrors.NewBusinessError(http.StatusBadRequest, "invalid path params", err)
 	}
 
+	err = BindHeaders(r, dest.Interface())
+	if err != nil {
+		return reflect.Value{}, sourceError("invalid request headers", err)
+	}
+
+	err = BindCookies(r, dest.Interface())
+	if err != nil {
+		return reflect.Value{}, sourceError("invalid request cookies", err)
+	}
+
 	err = b.BindBody(contentType, r, dest)
 	if err != nil {
 		return reflect.Value{}, err
@@ -75,16 +91,23 @@ func (b *RequestBinder) Bind(
 		return elem, nil
 	}
 	formattedDetails := formatDetails(details)
+	for key, name := range sourceNames(destType) {
+		detail, ok := formattedDetails[key]
+		if ok {
+			delete(formattedDetails, key)
+			formattedDetails[name] = detail
+		}
+	}
 	return reflect.Value{}, apierrors.NewBusinessError(
 		http.StatusBadRequest,
 		"invalid request body",
 		errors.Errorf("validation errors: %v", formattedDetails),
-	).WithDetails(formattedDetails)
+	).WithFieldErrors(formattedDetails)
 }
 
 /**
  * BindBody binds the request body based on the content type.
- * It supports binding for form data, JSON, and XML content types.
+ * It supports binding for form data, multipart form data with files, JSON, and XML content types.
  *
  * Parameters:
  * - contentType: the content type of the request
@@ -100,43 +123,68 @@ func (b *RequestBinder) BindBody(
 ) error {
 	var err error
 	switch {
-	case strings.HasPrefix(contentType, MIMEApplicationForm),
-		strings.HasPrefix(contentType, MIMEMultipartForm):
-		err = BindData(r.Form, dest.Interface(), FormTag)
+	case strings.HasPrefix(contentType, MIMEMultipartForm):
+		return b.bindMultipart(r, dest)
+	case strings.HasPrefix(contentType, MIMEApplicationForm):
+		err = r.ParseForm()
+		if err == nil {
+			err = BindData(r.Form, dest.Interface(), FormTag)
+		}
 	case strings.HasPrefix(contentType, MIMEApplicationJSON):
-		err = json.NewDecoder(r.Body).Decode(dest.Interface())
+		return b.bindJson(r, dest)
 	case strings.HasPrefix(contentType, MIMEApplicationXML),
 		strings.HasPrefix(contentType, MIMETextXML):
-		err = bindXml(r.Body, dest)
+		return bindXml(r.Body, dest)
 	default:
 		return nil
 	}
-	if err != nil {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return readBodyError(err)
+	case err != nil:
 		return apierrors.NewBusinessError(
 			http.StatusBadRequest,
 			"invalid request body",
 			err,
 		)
+	default:
+		return nil
 	}
-	return nil
 }
 
 func formatDetails(details map[string]string) map[string]any {
 	result := make(map[string]any, len(details))
 	for k, v := range details {
-		arr := []rune(k)
-		arr[0] = unicode.ToLower(arr[0])
-		result[string(arr)] = v
+		result[formatDetailKey(k)] = v
 	}
 	return result
 }
 
+func formatDetailKey(key string) string {
+	arr := []rune(key)
+	arr[0] = unicode.ToLower(arr[0])
+	return string(arr)
+}
+
+func sourceError(msg string, err error) error {
+	apiErr := apierrors.NewBusinessError(http.StatusBadRequest, msg, err)
+	var fieldErr FieldError
+	if errors.As(err, &fieldErr) {
+		apiErr = apiErr.WithFieldErrors(map[string]any{fieldErr.Name: fieldErr.Err.Error()})
+	}
+	return apiErr
+}
+
 func bindXml(reader io.Reader, dest reflect.Value) error {
 	err := xml.NewDecoder(reader).Decode(dest.Interface())
 
 	var ute *xml.UnsupportedTypeError
 	var se *xml.SyntaxError
+	var maxBytesErr *http.MaxBytesError
 	switch {
+	case errors.As(err, &maxBytesErr):
+		return readBodyError(err)
 	case errors.As(err, &ute):
 		return apierrors.NewBusinessError(http.StatusBadRequest,
 			fmt.Sprintf("Unsupported type error: type=%v, error=%v", ute.Type, ute.Error()), err)
@@ -1,19 +1,26 @@
 package binder_test
 
 import (
+	"bytes"
 	"context"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Falokut/go-kit/http/router"
+	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
 
 	"github.com/Falokut/go-kit/http/apierrors"
 	"github.com/Falokut/go-kit/http/endpoint/binder"
+	"github.com/Falokut/go-kit/http/types"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -89,3 +96,287 @@ func addPathParams(r *http.Request, params map[string]string) *http.Request {
 	ctx := context.WithValue(r.Context(), httprouter.ParamsKey, p)
 	return r.WithContext(ctx)
 }
+
+type uploadRequest struct {
+	Title  string                  `form:"title"`
+	Avatar *multipart.FileHeader   `form:"avatar" maxSize:"10B"`
+	Photos []*multipart.FileHeader `form:"photos"`
+}
+
+type streamUploadRequest struct {
+	Title string            `form:"title"`
+	File  *types.FileStream `form:"file" maxSize:"8B"`
+}
+
+func (s *RequestBinderSuite) Test_Bind_MultipartFiles() {
+	req := newMultipartRequest(s.T(), []multipartPart{
+		{name: "title", value: "hello"},
+		{name: "avatar", filename: "a.png", value: "avatar"},
+		{name: "photos", filename: "1.png", value: "first"},
+		{name: "photos", filename: "2.png", value: "second"},
+	})
+
+	val, err := s.binder.Bind(s.T().Context(), req.Header.Get("Content-Type"), req, reflect.TypeOf(uploadRequest{}))
+	s.Require().NoError(err)
+	result, ok := val.Interface().(uploadRequest)
+	s.Require().True(ok)
+	s.Equal("hello", result.Title)
+	s.Require().NotNil(result.Avatar)
+	s.Equal("a.png", result.Avatar.Filename)
+	s.Require().Len(result.Photos, 2)
+	s.Equal("2.png", result.Photos[1].Filename)
+}
+
+func (s *RequestBinderSuite) Test_Bind_MultipartFileTooLarge() {
+	req := newMultipartRequest(s.T(), []multipartPart{
+		{name: "avatar", filename: "a.png", value: "too large avatar"},
+	})
+
+	_, err := s.binder.Bind(s.T().Context(), req.Header.Get("Content-Type"), req, reflect.TypeOf(uploadRequest{}))
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusRequestEntityTooLarge, apiErr.HttpStatusCode())
+	s.Contains(apiErr.Details, "avatar")
+}
+
+func (s *RequestBinderSuite) Test_Bind_MultipartStream() {
+	req := newMultipartRequest(s.T(), []multipartPart{
+		{name: "title", value: "hello"},
+		{name: "file", filename: "data.txt", value: "12345678"},
+	})
+
+	val, err := s.binder.Bind(s.T().Context(), req.Header.Get("Content-Type"), req, reflect.TypeOf(streamUploadRequest{}))
+	s.Require().NoError(err)
+	result, ok := val.Interface().(streamUploadRequest)
+	s.Require().True(ok)
+	s.Equal("hello", result.Title)
+	s.Require().NotNil(result.File)
+	s.Equal("data.txt", result.File.Filename)
+	data, err := io.ReadAll(result.File)
+	s.Require().NoError(err)
+	s.Equal("12345678", string(data))
+
+	req = newMultipartRequest(s.T(), []multipartPart{
+		{name: "file", filename: "data.txt", value: "123456789"},
+	})
+	val, err = s.binder.Bind(s.T().Context(), req.Header.Get("Content-Type"), req, reflect.TypeOf(streamUploadRequest{}))
+	s.Require().NoError(err)
+	result, ok = val.Interface().(streamUploadRequest)
+	s.Require().True(ok)
+	_, err = io.ReadAll(result.File)
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusRequestEntityTooLarge, apiErr.HttpStatusCode())
+}
+
+type multipartPart struct {
+	name     string
+	filename string
+	value    string
+}
+
+func newMultipartRequest(t *testing.T, parts []multipartPart) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, part := range parts {
+		var (
+			w   io.Writer
+			err error
+		)
+		if part.filename != "" {
+			w, err = writer.CreateFormFile(part.name, part.filename)
+		} else {
+			w, err = writer.CreateFormField(part.name)
+		}
+		require.NoError(t, err)
+		_, err = io.WriteString(w, part.value)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+type headerRequest struct {
+	TenantId  string    `header:"X-Tenant-Id"`
+	Ids       []int     `header:"X-Ids"`
+	Limit     *int      `header:"X-Limit"`
+	Since     time.Time `header:"X-Since"`
+	Session   string    `cookie:"session"`
+	Untagged  string
+	Addresses []netip.Addr `header:"X-Address"`
+}
+
+func (s *RequestBinderSuite) Test_Bind_HeadersAndCookies() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-Id", "tenant")
+	req.Header.Add("X-Ids", "1, 2")
+	req.Header.Add("X-Ids", "3")
+	req.Header.Set("X-Limit", "10")
+	req.Header.Set("X-Since", "2024-01-02T03:04:05Z")
+	req.Header.Set("X-Address", "127.0.0.1,::1")
+	req.Header.Set("Untagged", "value")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "secret"})
+
+	val, err := s.binder.Bind(s.T().Context(), "", req, reflect.TypeOf(headerRequest{}))
+	s.Require().NoError(err)
+	result, ok := val.Interface().(headerRequest)
+	s.Require().True(ok)
+	s.Equal("tenant", result.TenantId)
+	s.Equal([]int{1, 2, 3}, result.Ids)
+	s.Require().NotNil(result.Limit)
+	s.Equal(10, *result.Limit)
+	s.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), result.Since)
+	s.Equal([]netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")}, result.Addresses)
+	s.Equal("secret", result.Session)
+	s.Empty(result.Untagged)
+}
+
+func (s *RequestBinderSuite) Test_Bind_InvalidHeader() {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Limit", "ten")
+
+	_, err := s.binder.Bind(s.T().Context(), "", req, reflect.TypeOf(headerRequest{}))
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusBadRequest, apiErr.HttpStatusCode())
+	s.Contains(apiErr.Details, "X-Limit")
+}
+
+func (s *RequestBinderSuite) Test_Bind_HeaderValidationDetails() {
+	s.binder = binder.NewRequestBinder(&stubValidator{
+		ok:      false,
+		details: map[string]string{"tenantId": "required", "session": "required"},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := s.binder.Bind(s.T().Context(), "", req, reflect.TypeOf(headerRequest{}))
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(map[string]any{"X-Tenant-Id": "required", "session": "required"}, apiErr.Details)
+}
+
+type jsonItem struct {
+	Price int `json:"price"`
+}
+
+type jsonRequest struct {
+	Name  string
+	Items []jsonItem
+}
+
+func (s *RequestBinderSuite) Test_Bind_JsonErrorLocation() {
+	body := `{"name":"a","items":[{"price":1},{"price":"free"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+	_, err := s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(jsonRequest{}))
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusBadRequest, apiErr.HttpStatusCode())
+	s.Equal("$.items[1].price", apiErr.Details["path"])
+	s.EqualValues(strings.Index(body, `"free"`), apiErr.Details["offset"])
+}
+
+func (s *RequestBinderSuite) Test_Bind_StrictJson() {
+	s.binder = binder.NewRequestBinder(&stubValidator{ok: true}, binder.WithStrictJson(true))
+	tests := []struct {
+		body         string
+		expectedPath string
+	}{
+		{body: `{"name":"a","items":[{"price":1,"discount":2}]}`, expectedPath: "$.items[0].discount"},
+		{body: `{"name":"a","name":"b"}`, expectedPath: "$.name"},
+		{body: `{"name":"a","Name":"b"}`, expectedPath: "$.Name"},
+		{body: `{"name":"a"`, expectedPath: "$"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		_, err := s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(jsonRequest{}))
+		apiErr := apierrors.Error{}
+		s.Require().ErrorAs(err, &apiErr, test.body)
+		s.Equal(test.expectedPath, apiErr.Details["path"], test.body)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"Name":"a","items":[{"price":1}]}`))
+	val, err := s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(jsonRequest{}))
+	s.Require().NoError(err)
+	s.Equal("a", val.FieldByName("Name").String())
+
+	// keys of map are case-sensitive
+	type mapRequest struct {
+		Attrs map[string]int
+	}
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"attrs":{"a":1,"A":2}}`))
+	val, err = s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(mapRequest{}))
+	s.Require().NoError(err)
+	s.Equal(map[string]int{"a": 1, "A": 2}, val.Interface().(mapRequest).Attrs)
+}
+
+func (s *RequestBinderSuite) Test_Bind_StrictJsonTextUnmarshaler() {
+	type request struct {
+		Id       uuid.UUID
+		ParentId *uuid.UUID
+	}
+	s.binder = binder.NewRequestBinder(&stubValidator{ok: true}, binder.WithStrictJson(true))
+	id := uuid.New()
+	parentId := uuid.New()
+	tests := []struct {
+		name             string
+		body             string
+		expected         request
+		expectedPath     string
+		expectedErrorMsg string
+	}{
+		{
+			name:     "uuid",
+			body:     `{"id":"` + id.String() + `","parentId":"` + parentId.String() + `"}`,
+			expected: request{Id: id, ParentId: &parentId},
+		},
+		{
+			name:     "null pointer",
+			body:     `{"id":"` + id.String() + `","parentId":null}`,
+			expected: request{Id: id},
+		},
+		{
+			name:         "invalid uuid",
+			body:         `{"id":"` + id.String() + `","parentId":"abc"}`,
+			expectedPath: "$.parentId",
+		},
+		{
+			name:             "not a string",
+			body:             `{"id":[1,2]}`,
+			expectedPath:     "$.id",
+			expectedErrorMsg: "expected string, got array",
+		},
+	}
+	for _, test := range tests {
+		s.Run(test.name, func() {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			val, err := s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(request{}))
+			if test.expectedPath == "" {
+				s.Require().NoError(err)
+				s.Equal(test.expected, val.Interface())
+				return
+			}
+			apiErr := apierrors.Error{}
+			s.Require().ErrorAs(err, &apiErr)
+			s.Equal(http.StatusBadRequest, apiErr.HttpStatusCode())
+			s.Equal(test.expectedPath, apiErr.Details["path"])
+			if test.expectedErrorMsg != "" {
+				s.Equal(test.expectedErrorMsg, apiErr.Details["reason"])
+			}
+		})
+	}
+}
+
+func (s *RequestBinderSuite) Test_Bind_BodyTooLarge() {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"too large"}`))
+	req.Body = http.MaxBytesReader(httptest.NewRecorder(), req.Body, 5)
+
+	_, err := s.binder.Bind(s.T().Context(), "application/json", req, reflect.TypeOf(jsonRequest{}))
+	apiErr := apierrors.Error{}
+	s.Require().ErrorAs(err, &apiErr)
+	s.Equal(http.StatusRequestEntityTooLarge, apiErr.HttpStatusCode())
+}
@@ -4,6 +4,14 @@ const (
 	PathTag  = "path"
 	QueryTag = "query"
 	FormTag  = "form"
+	// HeaderTag and CookieTag bind only fields with explicit tag, e.g. `header:"X-Tenant-Id"`
+	HeaderTag = "header"
+	CookieTag = "cookie"
+
+	// MaxSizeTag limits size of multipart file field, e.g. `maxSize:"5MB"`
+	MaxSizeTag = "maxSize"
+
+	defaultMultipartMemory = 32 << 20
 
 	SkipParamFieldName = "-"
 
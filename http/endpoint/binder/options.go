@@ -0,0 +1,18 @@
+package binder
+
+type Option func(b *RequestBinder)
+
+// WithMultipartMemory sets how many bytes of multipart files are kept in memory,
+// the rest is stored in temporary files, default = 32MB
+func WithMultipartMemory(maxMemory int64) Option {
+	return func(b *RequestBinder) {
+		b.multipartMemory = maxMemory
+	}
+}
+
+// WithStrictJson makes binder reject json bodies with unknown fields and duplicate keys
+func WithStrictJson(strict bool) Option {
+	return func(b *RequestBinder) {
+		b.strictJson = strict
+	}
+}
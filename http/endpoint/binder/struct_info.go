@@ -13,6 +13,9 @@ type fieldInfo struct {
 	elemKind  reflect.Kind
 	isPtr     bool
 	anonymous bool
+	// maxSize limits size of file field, zero if not limited
+	maxSize    int64
+	maxSizeErr error
 }
 
 type structInfo struct {
@@ -61,6 +64,10 @@ func getStructInfo(t reflect.Type, tag string) *structInfo {
 		if fieldName == SkipParamFieldName {
 			continue
 		}
+		_, tagged := field.Tag.Lookup(tag)
+		if isExplicitTag(tag) && !tagged && !field.Anonymous {
+			continue
+		}
 
 		fi := fieldInfo{
 			index:     i,
@@ -71,6 +78,11 @@ func getStructInfo(t reflect.Type, tag string) *structInfo {
 			anonymous: field.Anonymous,
 		}
 
+		maxSize, ok := field.Tag.Lookup(MaxSizeTag)
+		if ok {
+			fi.maxSize, fi.maxSizeErr = parseSize(maxSize)
+		}
+
 		if fi.isSlice {
 			fi.elemKind = field.Type.Elem().Kind()
 		} else if fi.isPtr && field.Type.Elem().Kind() == reflect.Slice {
@@ -84,3 +96,8 @@ func getStructInfo(t reflect.Type, tag string) *structInfo {
 	structCache[key] = info
 	return info
 }
+
+// isExplicitTag reports whether fields without tag are not bound, e.g. header would be bound from field name otherwise
+func isExplicitTag(tag string) bool {
+	return tag == HeaderTag || tag == CookieTag
+}
@@ -4,15 +4,25 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/pkg/errors"
 )
 
+// nolint:gochecknoglobals
+var streamContentTypes = []string{
+	"text/event-stream",
+	"application/x-ndjson",
+}
+
 type Buffer struct {
 	http.ResponseWriter
 	requestBuffer  *bytes.Buffer
 	responseBuffer *bytes.Buffer
 	statusCode     int
+	wroteHeader    bool
+	stream         bool
 }
 
 // nolint:mnd
@@ -26,15 +36,24 @@ func New() *Buffer {
 func (m *Buffer) Reset(w http.ResponseWriter) {
 	m.ResponseWriter = w
 	m.statusCode = 0
+	m.wroteHeader = false
+	m.stream = false
 	m.responseBuffer.Reset()
 	m.requestBuffer.Reset()
 }
 
 func (m *Buffer) Write(b []byte) (int, error) {
+	if !m.wroteHeader {
+		m.detectStream()
+	}
 	n, err := m.ResponseWriter.Write(b)
 	if err != nil {
 		return n, errors.WithMessage(err, "write to response writer")
 	}
+	// body of long-lived streams is not captured
+	if m.stream {
+		return n, nil
+	}
 
 	n, err = m.responseBuffer.Write(b)
 	if err != nil {
@@ -45,10 +64,35 @@ func (m *Buffer) Write(b []byte) (int, error) {
 }
 
 func (m *Buffer) WriteHeader(statusCode int) {
-	m.statusCode = statusCode
+	if !m.wroteHeader {
+		m.statusCode = statusCode
+		m.detectStream()
+	}
 	m.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Flush sends buffered data to client, it is required by streaming responses
+func (m *Buffer) Flush() {
+	_ = http.NewResponseController(m.ResponseWriter).Flush()
+}
+
+func (m *Buffer) Unwrap() http.ResponseWriter {
+	return m.ResponseWriter
+}
+
+// IsStream reports whether response is a long-lived stream, e.g. server-sent events
+func (m *Buffer) IsStream() bool {
+	return m.stream
+}
+
+func (m *Buffer) detectStream() {
+	m.wroteHeader = true
+	contentType := m.Header().Get("Content-Type")
+	m.stream = slices.ContainsFunc(streamContentTypes, func(streamContentType string) bool {
+		return strings.HasPrefix(contentType, streamContentType)
+	})
+}
+
 func (m *Buffer) ResponseBody() []byte {
 	return m.responseBuffer.Bytes()
 }
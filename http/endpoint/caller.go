@@ -14,6 +14,12 @@ type ResponseWriter interface {
 	Write(w http.ResponseWriter) error
 }
 
+// RequestResponseWriter is a ResponseWriter which depends on request, e.g. handles conditional headers.
+// It is used instead of Write if implemented by result.
+type RequestResponseWriter interface {
+	WriteRequest(r *http.Request, w http.ResponseWriter) error
+}
+
 // param represents a function parameter along with its index
 // and a function for extracting its value from the HTTP request.
 type param struct {
@@ -88,6 +94,7 @@ func NewCaller(
 
 // Handle prepares the arguments for the handler function from the HTTP request,
 // calls the function, and writes the result to the http.ResponseWriter.
+// Stream results (Sse, Ndjson) are written until client disconnects.
 // If the result implements ResponseWriter, it is written directly; otherwise,
 // it is passed to the configured ResponseBodyMapper.
 func (h *Caller) Handle(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -96,6 +103,12 @@ func (h *Caller) Handle(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	if h.reqBodyIndex != -1 {
 		contentType := r.Header.Get("Content-Type")
 		value, err := h.bodyExtractor.Bind(ctx, contentType, r, h.reqBodyType)
+		if r.MultipartForm != nil {
+			// remove temporary files of multipart form after handler
+			defer func() {
+				_ = r.MultipartForm.RemoveAll()
+			}()
+		}
 		if err != nil {
 			return err
 		}
@@ -127,15 +140,53 @@ func (h *Caller) Handle(ctx context.Context, w http.ResponseWriter, r *http.Requ
 		return err
 	}
 
+	stream, ok := result.(streamResult)
+	if ok {
+		return stream.writeStream(ctx, w)
+	}
+
+	requestWriter, ok := result.(RequestResponseWriter)
+	if ok {
+		return requestWriter.WriteRequest(r, w)
+	}
+
 	writer, ok := result.(ResponseWriter)
 	if ok {
 		return writer.Write(w)
 	}
 
-	err = h.bodyMapper.Map(result, w)
-	if err != nil {
-		return err
+	status, ok := result.(statusResult)
+	if ok {
+		return h.writeStatusResult(w, r, status)
 	}
 
+	return h.mapBody(w, r, result)
+}
+
+func (h *Caller) writeStatusResult(w http.ResponseWriter, r *http.Request, result statusResult) error {
+	statusCode, header, body := result.response()
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	sw := &statusWriter{ResponseWriter: w, statusCode: statusCode}
+	if statusCode != http.StatusNoContent && statusCode != http.StatusNotModified {
+		err := h.mapBody(sw, r, body)
+		if err != nil {
+			return err
+		}
+	}
+	sw.WriteHeader(statusCode)
 	return nil
 }
+
+func (h *Caller) mapBody(w http.ResponseWriter, r *http.Request, result any) error {
+	mapper, ok := h.bodyMapper.(RequestBodyMapper)
+	if ok {
+		return mapper.MapRequest(r, result, w)
+	}
+	return h.bodyMapper.Map(result, w)
+}
@@ -0,0 +1,357 @@
+package endpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingZstd    = "zstd"
+
+	defaultCompressMinSize = 1024
+)
+
+// nolint:gochecknoglobals
+var (
+	// compressedContentTypes are never compressed even if allowed by config
+	compressedContentTypes = []string{
+		"image/", "video/", "audio/", "font/woff",
+		"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+		"application/x-7z-compressed", "application/x-rar-compressed", "application/x-bzip2",
+		"application/pdf", "application/octet-stream",
+	}
+	encoderPools = map[string]*sync.Pool{
+		EncodingGzip: {New: func() any {
+			return gzip.NewWriter(io.Discard)
+		}},
+		EncodingDeflate: {New: func() any {
+			return zlib.NewWriter(io.Discard)
+		}},
+		EncodingZstd: {New: func() any {
+			encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1))
+			return encoder
+		}},
+	}
+)
+
+// CompressConfig configures Compress middleware
+type CompressConfig struct {
+	// Encodings are supported encodings in order of preference if client accepts several with the same weight,
+	// EncodingGzip, EncodingDeflate and EncodingZstd are available
+	Encodings []string
+	// MinSize is a min size of response body in bytes to compress
+	MinSize int
+	// ContentTypes are prefixes of compressed content types
+	ContentTypes []string
+}
+
+// DefaultCompressConfig returns config which compresses text responses of at least 1KB by gzip or deflate
+func DefaultCompressConfig() CompressConfig {
+	return CompressConfig{
+		Encodings: []string{EncodingGzip, EncodingDeflate},
+		MinSize:   defaultCompressMinSize,
+		ContentTypes: []string{
+			"application/json",
+			"application/problem+json",
+			"application/xml",
+			"application/javascript",
+			"application/x-ndjson",
+			"text/",
+		},
+	}
+}
+
+// Compress compresses response body by encoding negotiated with Accept-Encoding header.
+// Body is buffered until MinSize, smaller bodies, partial content, already encoded responses
+// and compressed content types are written as is.
+// Flushed responses (e.g. Sse) are not compressed if less than MinSize is written before the first flush
+func Compress(cfg CompressConfig) http2.Middleware {
+	encodings := slices.DeleteFunc(slices.Clone(cfg.Encodings), func(encoding string) bool {
+		return encoderPools[encoding] == nil
+	})
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.Header.Get("Upgrade") != "" {
+				return next(ctx, w, r)
+			}
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), encodings)
+			if encoding == "" {
+				return next(ctx, w, r)
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				cfg:            cfg,
+				encoding:       encoding,
+			}
+			err := next(ctx, cw, r)
+			closeErr := cw.close()
+			if err != nil {
+				return err
+			}
+			return closeErr
+		}
+	}
+}
+
+// Decompress decodes request body with Content-Encoding gzip, deflate or zstd before binding,
+// unsupported encoding is rejected with 415.
+// Decoded body is limited by maxSize to prevent decompression bombs, zero means no limit
+func Decompress(maxSize int64) http2.Middleware {
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if encoding == "" || encoding == "identity" {
+				return next(ctx, w, r)
+			}
+
+			body, err := decoder(encoding, r.Body)
+			if err != nil {
+				return err
+			}
+			defer body.Close()
+			if maxSize > 0 {
+				body = http.MaxBytesReader(w, body, maxSize)
+			}
+
+			r.Body = body
+			r.ContentLength = -1
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			return next(ctx, w, r)
+		}
+	}
+}
+
+func decoder(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	var err error
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		reader, err = gzip.NewReader(body)
+	case EncodingDeflate:
+		reader, err = zlib.NewReader(body)
+	case EncodingZstd:
+		var zstdReader *zstd.Decoder
+		zstdReader, err = zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+		if err == nil {
+			reader = zstdReader.IOReadCloser()
+		}
+	default:
+		return nil, apierrors.NewUnsupportedMediaTypeError("unsupported content encoding " + encoding)
+	}
+	if err != nil {
+		return nil, apierrors.NewBusinessError(
+			http.StatusBadRequest,
+			"invalid "+encoding+" request body",
+			errors.WithMessage(err, "create decoder"),
+		)
+	}
+	return reader, nil
+}
+
+// negotiateEncoding returns supported encoding with the highest weight in Accept-Encoding header
+func negotiateEncoding(acceptEncoding string, encodings []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for spec := range strings.SplitSeq(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(spec, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if name == "*" {
+			wildcard = weight
+			continue
+		}
+		weights[name] = weight
+	}
+
+	best := ""
+	bestWeight := 0.0
+	for _, encoding := range encodings {
+		weight, ok := weights[encoding]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestWeight {
+			best = encoding
+			bestWeight = weight
+		}
+	}
+	return best
+}
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressWriter buffers body until MinSize and decides whether to compress response
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      CompressConfig
+	encoding string
+
+	statusCode int
+	buf        bytes.Buffer
+	decided    bool
+	encoder    encoder
+}
+
+func (w *compressWriter) WriteHeader(statusCode int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if statusCode < http.StatusOK {
+		// informational responses are written immediately
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		return w.write(b)
+	}
+	w.buf.Write(b)
+	if w.buf.Len() < w.cfg.MinSize {
+		return len(b), nil
+	}
+	err := w.decide()
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.encoder != nil {
+		return w.encoder.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) decide() error {
+	w.decided = true
+	statusCode := w.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	if w.shouldCompress(statusCode) {
+		header := w.Header()
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		pool := encoderPools[w.encoding]
+		w.encoder, _ = pool.Get().(encoder)
+		w.encoder.Reset(w.ResponseWriter)
+	}
+
+	if w.statusCode != 0 {
+		w.ResponseWriter.WriteHeader(statusCode)
+	}
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.write(w.buf.Bytes())
+	w.buf.Reset()
+	if err != nil {
+		return errors.WithMessage(err, "write buffered response")
+	}
+	return nil
+}
+
+func (w *compressWriter) shouldCompress(statusCode int) bool {
+	if w.buf.Len() == 0 || w.buf.Len() < w.cfg.MinSize {
+		return false
+	}
+	if statusCode == http.StatusPartialContent || statusCode == http.StatusNoContent ||
+		statusCode == http.StatusNotModified {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(w.buf.Bytes())
+	}
+	contentType = strings.ToLower(contentType)
+	hasPrefix := func(prefix string) bool {
+		return strings.HasPrefix(contentType, prefix)
+	}
+	return slices.ContainsFunc(w.cfg.ContentTypes, hasPrefix) &&
+		!slices.ContainsFunc(compressedContentTypes, hasPrefix)
+}
+
+func (w *compressWriter) close() error {
+	if !w.decided {
+		if w.statusCode == 0 && w.buf.Len() == 0 {
+			// nothing is written, e.g. handler returned error
+			return nil
+		}
+		err := w.decide()
+		if err != nil {
+			return err
+		}
+	}
+	if w.encoder == nil {
+		return nil
+	}
+
+	err := w.encoder.Close()
+	w.encoder.Reset(io.Discard)
+	encoderPools[w.encoding].Put(w.encoder)
+	w.encoder = nil
+	if err != nil {
+		return errors.WithMessage(err, "close "+w.encoding+" encoder")
+	}
+	return nil
+}
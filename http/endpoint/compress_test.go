@@ -0,0 +1,126 @@
+package endpoint_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/endpoint"
+	"github.com/Falokut/go-kit/log"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func compressHandler(cfg endpoint.CompressConfig, statusCode int, contentType string, body string) http2.HandlerFunc {
+	return endpoint.Compress(cfg)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(statusCode)
+		_, err := io.WriteString(w, body)
+		return err
+	})
+}
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cfg := endpoint.DefaultCompressConfig()
+	cfg.Encodings = append(cfg.Encodings, endpoint.EncodingZstd)
+	body := `[` + strings.Repeat(`{"id":1},`, 200) + `{"id":2}]`
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "deflate;q=0.5, gzip")
+	rec := httptest.NewRecorder()
+	require.NoError(compressHandler(cfg, http.StatusCreated, "application/json", body)(req.Context(), rec, req))
+	require.Equal(http.StatusCreated, rec.Code)
+	require.Equal(endpoint.EncodingGzip, rec.Header().Get("Content-Encoding"))
+	require.Equal("Accept-Encoding", rec.Header().Get("Vary"))
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(err)
+	decoded, err := io.ReadAll(reader)
+	require.NoError(err)
+	require.Equal(body, string(decoded))
+
+	req.Header.Set("Accept-Encoding", "gzip;q=0, zstd")
+	rec = httptest.NewRecorder()
+	require.NoError(compressHandler(cfg, http.StatusOK, "application/json", body)(req.Context(), rec, req))
+	require.Equal(endpoint.EncodingZstd, rec.Header().Get("Content-Encoding"))
+	zstdReader, err := zstd.NewReader(rec.Body)
+	require.NoError(err)
+	decoded, err = io.ReadAll(zstdReader)
+	require.NoError(err)
+	require.Equal(body, string(decoded))
+
+	skipped := []struct {
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{http.StatusOK, "application/json", `{"id":1}`},
+		{http.StatusOK, "image/png", body},
+		{http.StatusOK, "application/octet-stream", body},
+		{http.StatusPartialContent, "application/json", body},
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	for _, test := range skipped {
+		rec = httptest.NewRecorder()
+		require.NoError(compressHandler(cfg, test.statusCode, test.contentType, test.body)(req.Context(), rec, req))
+		require.Equal(test.statusCode, rec.Code)
+		require.Empty(rec.Header().Get("Content-Encoding"))
+		require.Equal(test.body, rec.Body.String())
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type request struct {
+		Name string
+	}
+	wrapper := endpoint.DefaultWrapper(log.New(log.WithOutput(io.Discard)), func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	}).WithMiddlewares(endpoint.Decompress(1024))
+	handler := wrapper.Endpoint(func(req request) string {
+		return req.Name
+	})
+
+	compressed := bytes.NewBuffer(nil)
+	gzipWriter := gzip.NewWriter(compressed)
+	_, err := gzipWriter.Write([]byte(`{"name":"test"}`))
+	require.NoError(err)
+	require.NoError(gzipWriter.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(compressed.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(http.StatusOK, rec.Code)
+	require.JSONEq(`"test"`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "br")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(http.StatusUnsupportedMediaType, rec.Code)
+
+	compressed.Reset()
+	gzipWriter.Reset(compressed)
+	_, err = gzipWriter.Write([]byte(`{"name":"` + strings.Repeat("a", 2048) + `"}`))
+	require.NoError(err)
+	require.NoError(gzipWriter.Close())
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(compressed.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+}
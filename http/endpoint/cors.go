@@ -0,0 +1,188 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+)
+
+const (
+	originHeader                        = "Origin"
+	varyHeader                          = "Vary"
+	accessControlRequestMethodHeader    = "Access-Control-Request-Method"
+	accessControlRequestHeadersHeader   = "Access-Control-Request-Headers"
+	accessControlAllowOriginHeader      = "Access-Control-Allow-Origin"
+	accessControlAllowMethodsHeader     = "Access-Control-Allow-Methods"
+	accessControlAllowHeadersHeader     = "Access-Control-Allow-Headers"
+	accessControlExposeHeadersHeader    = "Access-Control-Expose-Headers"
+	accessControlAllowCredentialsHeader = "Access-Control-Allow-Credentials"
+	accessControlMaxAgeHeader           = "Access-Control-Max-Age"
+)
+
+// CorsConfig configures Cors middleware
+type CorsConfig struct {
+	// AllowedOrigins is a list of allowed origins, "*" allows any origin,
+	// wildcard subdomains are supported, e.g. "https://*.example.com"
+	AllowedOrigins []string
+	// AllowOriginFunc is checked if origin is not in AllowedOrigins, may be nil
+	AllowOriginFunc func(origin string) bool
+	// AllowedMethods default = GET, HEAD, POST, PUT, PATCH, DELETE
+	AllowedMethods []string
+	// AllowedHeaders default = headers from Access-Control-Request-Headers of preflight request
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge is a duration preflight response may be cached, not sent if zero
+	MaxAge time.Duration
+}
+
+type cors struct {
+	cfg            CorsConfig
+	allowAll       bool
+	origins        []string
+	wildcards      [][2]string
+	allowedMethods string
+	allowedHeaders string
+	exposedHeaders string
+	maxAge         string
+}
+
+func newCors(cfg CorsConfig) cors {
+	c := cors{cfg: cfg}
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			c.allowAll = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			c.wildcards = append(c.wildcards, [2]string{prefix, suffix})
+		default:
+			c.origins = append(c.origins, origin)
+		}
+	}
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+	c.allowedMethods = strings.Join(methods, ", ")
+	c.allowedHeaders = strings.Join(cfg.AllowedHeaders, ", ")
+	c.exposedHeaders = strings.Join(cfg.ExposedHeaders, ", ")
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return c
+}
+
+// Cors sets CORS headers for allowed origins and responds to preflight requests without calling next.
+// Preflight requests reach endpoint only if OPTIONS handler is registered,
+// otherwise use CorsPreflightHandler with router.AutoOptions
+func Cors(cfg CorsConfig) http2.Middleware {
+	c := newCors(cfg)
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if isPreflight(r) {
+				c.preflight(w, r)
+				return nil
+			}
+			c.actual(w, r)
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// CorsPreflightHandler responds to preflight requests, e.g. router.New().AutoOptions(true, CorsPreflightHandler(cfg))
+func CorsPreflightHandler(cfg CorsConfig) http.Handler {
+	c := newCors(cfg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPreflight(r) {
+			c.preflight(w, r)
+		}
+	})
+}
+
+func (c cors) preflight(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Add(varyHeader, originHeader)
+	header.Add(varyHeader, accessControlRequestMethodHeader)
+	header.Add(varyHeader, accessControlRequestHeadersHeader)
+
+	origin := r.Header.Get(originHeader)
+	if !c.isOriginAllowed(origin) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.setAllowOrigin(header, origin)
+	header.Set(accessControlAllowMethodsHeader, c.allowedMethods)
+	allowedHeaders := c.allowedHeaders
+	if allowedHeaders == "" {
+		allowedHeaders = r.Header.Get(accessControlRequestHeadersHeader)
+	}
+	if allowedHeaders != "" {
+		header.Set(accessControlAllowHeadersHeader, allowedHeaders)
+	}
+	if c.maxAge != "" {
+		header.Set(accessControlMaxAgeHeader, c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c cors) actual(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	header.Add(varyHeader, originHeader)
+	origin := r.Header.Get(originHeader)
+	if origin == "" || !c.isOriginAllowed(origin) {
+		return
+	}
+	c.setAllowOrigin(header, origin)
+	if c.exposedHeaders != "" {
+		header.Set(accessControlExposeHeadersHeader, c.exposedHeaders)
+	}
+}
+
+func (c cors) setAllowOrigin(header http.Header, origin string) {
+	if c.allowAll && !c.cfg.AllowCredentials {
+		header.Set(accessControlAllowOriginHeader, "*")
+	} else {
+		header.Set(accessControlAllowOriginHeader, origin)
+	}
+	if c.cfg.AllowCredentials {
+		header.Set(accessControlAllowCredentialsHeader, "true")
+	}
+}
+
+func (c cors) isOriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if c.allowAll {
+		return true
+	}
+	lowerOrigin := strings.ToLower(origin)
+	if slices.Contains(c.origins, lowerOrigin) {
+		return true
+	}
+	for _, wildcard := range c.wildcards {
+		if len(lowerOrigin) >= len(wildcard[0])+len(wildcard[1]) &&
+			strings.HasPrefix(lowerOrigin, wildcard[0]) &&
+			strings.HasSuffix(lowerOrigin, wildcard[1]) {
+			return true
+		}
+	}
+	return c.cfg.AllowOriginFunc != nil && c.cfg.AllowOriginFunc(origin)
+}
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get(originHeader) != "" &&
+		r.Header.Get(accessControlRequestMethodHeader) != ""
+}
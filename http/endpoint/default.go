@@ -14,7 +14,19 @@ type LogMiddleware http.Middleware
 // DefaultWrapper creates a standard HTTP endpoint wrapper with a set of default middlewares.
 //
 // It includes request body size limit, request ID generation, logging, error handling, and panic recovery.
-// Additional custom middlewares can be passed via restMiddlewares.
+// Response body is encoded as json, use Wrapper.WithBodyMapper(response.DefaultNegotiatingMapper())
+// to negotiate response body format by Accept header.
+// Additional custom middlewares can be passed via restMiddlewares, e.g. Cors, SecurityHeaders, Deadline or Compress:
+//
+//	endpoint.DefaultWrapper(logger, hlog.Log(logger, true),
+//		endpoint.Cors(corsConfig),
+//		endpoint.SecurityHeaders(endpoint.DefaultSecurityHeadersConfig()),
+//		endpoint.Deadline(5*time.Second),
+//		endpoint.Compress(endpoint.DefaultCompressConfig()),
+//		endpoint.Decompress(10*1024*1024),
+//	)
+//
+// Use Wrapper.WithMiddlewares to set middlewares for a single endpoint, e.g. a longer Deadline.
 //
 // This is the preferred entry point for quickly setting up endpoints with common functionality.
 func DefaultWrapper(logger log.Logger, logMiddleware LogMiddleware, restMiddlewares ...http.Middleware) Wrapper {
@@ -5,6 +5,7 @@
 // This middleware logs HTTP method, URL, and optionally the request and response bodies,
 // based on the provided configuration or default options. It supports logging body content
 // for the following content types: "application/json" and "text/xml" by default.
+// Response body of long-lived streams ("text/event-stream", "application/x-ndjson") is never captured.
 //
 // There are two ways to use the middleware:
 //   - Use Log() for simple body logging toggle
@@ -115,7 +116,11 @@ func middleware(logger log.Logger, cfg *logConfig) endpoint.LogMiddleware {
 				log.Int64("elapsedTimeMs", time.Since(now).Milliseconds()),
 			}
 			responseContentType := buf.Header().Get("Content-Type")
-			if cfg.logResponseBody && matchContentType(responseContentType, cfg.logBodyContentTypes) {
+			if buf.IsStream() {
+				// body of long-lived streams is not captured
+				responseLogFields = append(responseLogFields, log.Bool("stream", true))
+			} else if cfg.logResponseBody && buf.Header().Get("Content-Encoding") == "" &&
+				matchContentType(responseContentType, cfg.logBodyContentTypes) {
 				responseLogFields = append(responseLogFields, log.ByteString("responseBody", buf.ResponseBody()))
 			}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"time"
 
 	http2 "github.com/Falokut/go-kit/http"
 	"github.com/Falokut/go-kit/http/apierrors"
@@ -63,6 +64,11 @@ func ErrorHandler(logger log.Logger) http2.Middleware {
 			logFunc := log.LogLevelFuncForError(err, logger)
 			logFunc(ctx, err)
 
+			problemOpts, ok := apierrors.ProblemOptionsFromContext(ctx)
+			if ok {
+				return writeProblem(ctx, w, err, problemOpts)
+			}
+
 			var httpErr HttpError
 			if errors.As(err, &httpErr) {
 				err = httpErr.WriteError(w)
@@ -76,6 +82,24 @@ func ErrorHandler(logger log.Logger) http2.Middleware {
 	}
 }
 
+func writeProblem(ctx context.Context, w http.ResponseWriter, err error, opts apierrors.ProblemOptions) error {
+	if opts.Instance == "" {
+		opts.Instance = requestid.FromContext(ctx)
+	}
+
+	var problemErr ProblemError
+	if errors.As(err, &problemErr) {
+		return problemErr.WriteProblem(w, opts)
+	}
+	var httpErr HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr.WriteError(w)
+	}
+
+	// hide error details to prevent potential security leaks
+	return apierrors.NewInternalServiceError(err).WriteProblem(w, opts)
+}
+
 func RequestId() http2.Middleware {
 	return func(next http2.HandlerFunc) http2.HandlerFunc {
 		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -91,3 +115,33 @@ func RequestId() http2.Middleware {
 		}
 	}
 }
+
+// Deadline cancels handler context after timeout.
+// If handler fails after its context is done, error is replaced with
+// 504 (timeout exceeded) or 503 (request is canceled by server, e.g. on shutdown).
+// Handler must respect context cancellation
+func Deadline(timeout time.Duration) http2.Middleware {
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			err := next(deadlineCtx, w, r.WithContext(deadlineCtx))
+			if err == nil {
+				return nil
+			}
+
+			var httpErr HttpError
+			switch {
+			case errors.As(err, &httpErr):
+				return err
+			case ctx.Err() != nil:
+				return apierrors.NewServiceUnavailableError("request is canceled", err)
+			case errors.Is(deadlineCtx.Err(), context.DeadlineExceeded):
+				return apierrors.NewTimeoutError(fmt.Sprintf("request deadline %s exceeded", timeout), err)
+			default:
+				return err
+			}
+		}
+	}
+}
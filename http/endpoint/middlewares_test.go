@@ -0,0 +1,226 @@
+package endpoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/endpoint"
+	"github.com/Falokut/go-kit/http/endpoint/response"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/ratelimit"
+	"github.com/Falokut/go-kit/requestid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCors(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	mw := endpoint.Cors(endpoint.CorsConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+	called := false
+	handler := mw(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		called = true
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://api.example.org")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+	rec := httptest.NewRecorder()
+	require.NoError(handler(req.Context(), rec, req))
+	require.False(called)
+	require.Equal(http.StatusNoContent, rec.Code)
+	require.Equal("https://api.example.org", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal("true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal("Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal("3600", rec.Header().Get("Access-Control-Max-Age"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	rec = httptest.NewRecorder()
+	require.NoError(handler(req.Context(), rec, req))
+	require.True(called)
+	require.Empty(rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestDeadline(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	handler := endpoint.Deadline(10 * time.Millisecond)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	err := handler(req.Context(), httptest.NewRecorder(), req)
+
+	var apiErr apierrors.Error
+	require.ErrorAs(err, &apiErr)
+	require.Equal(http.StatusGatewayTimeout, apiErr.HttpStatusCode())
+	require.Equal(apierrors.ErrCodeTimeout, apiErr.ErrorCode)
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	cfg := endpoint.DefaultSecurityHeadersConfig()
+	cfg.StrictTransportSecurityMaxAge = time.Hour
+	handler := endpoint.SecurityHeaders(cfg)(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	require.NoError(handler(req.Context(), rec, req))
+	require.Equal("nosniff", rec.Header().Get("X-Content-Type-Options"))
+	require.Equal("max-age=3600", rec.Header().Get("Strict-Transport-Security"))
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(0), ratelimit.PerMinute(1))
+	trusted := netip.MustParsePrefix("10.0.0.0/8")
+	handler := endpoint.RateLimit(limiter, endpoint.ClientIpKey(trusted))(
+		func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			return nil
+		},
+	)
+
+	newRequest := func(remoteAddr string, forwardedFor string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		return req
+	}
+
+	req := newRequest("10.0.0.1:1234", "1.1.1.1, 10.0.0.2")
+	rec := httptest.NewRecorder()
+	require.NoError(handler(req.Context(), rec, req))
+	require.Equal("1", rec.Header().Get("RateLimit-Limit"))
+	require.Equal("0", rec.Header().Get("RateLimit-Remaining"))
+
+	req = newRequest("10.0.0.3:1234", "1.1.1.1")
+	rec = httptest.NewRecorder()
+	err := handler(req.Context(), rec, req)
+	var apiErr apierrors.Error
+	require.ErrorAs(err, &apiErr)
+	require.Equal(http.StatusTooManyRequests, apiErr.HttpStatusCode())
+	require.Equal("60", rec.Header().Get("Retry-After"))
+
+	// untrusted proxy headers are ignored
+	req = newRequest("2.2.2.2:1234", "1.1.1.1")
+	rec = httptest.NewRecorder()
+	require.NoError(handler(req.Context(), rec, req))
+}
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type created struct {
+		Id string
+	}
+	wrapper := endpoint.DefaultWrapper(log.New(log.WithOutput(io.Discard)), func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	})
+	handler := wrapper.Endpoint(func() (endpoint.Result[created], error) {
+		return endpoint.Created("/items/1", created{Id: "1"}).WithHeader("X-Item", "1"), nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items", nil))
+	require.Equal(http.StatusCreated, rec.Code)
+	require.Equal("/items/1", rec.Header().Get("Location"))
+	require.Equal("1", rec.Header().Get("X-Item"))
+	require.Equal("application/json", rec.Header().Get("Content-Type"))
+	require.JSONEq(`{"id":"1"}`, rec.Body.String())
+}
+
+func TestContentNegotiation(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type item struct {
+		Id string
+	}
+	wrapper := endpoint.DefaultWrapper(log.New(log.WithOutput(io.Discard)), func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	})
+	handler := func() (item, error) {
+		return item{Id: "1"}, nil
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set("Accept", "text/html,application/xml;q=0.9,*/*;q=0.8")
+	rec := httptest.NewRecorder()
+	wrapper.Endpoint(handler).ServeHTTP(rec, req)
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	rec = httptest.NewRecorder()
+	wrapper.WithBodyMapper(response.DefaultNegotiatingMapper()).Endpoint(handler).ServeHTTP(rec, req)
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal("application/xml", rec.Header().Get("Content-Type"))
+}
+
+func TestProblemDetails(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type request struct {
+		Name string `validate:"required"`
+	}
+	wrapper := endpoint.DefaultWrapper(log.New(log.WithOutput(io.Discard)), func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	}).WithProblemDetails("https://errors.example.com/")
+	handler := wrapper.Endpoint(func(req request) error {
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.RequestIdHeader, "req-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(http.StatusBadRequest, rec.Code)
+	require.Equal(apierrors.ProblemContentType, rec.Header().Get("Content-Type"))
+
+	problem := apierrors.Problem{}
+	require.NoError(json.Unmarshal(rec.Body.Bytes(), &problem))
+	require.Equal("https://errors.example.com/400", problem.Type)
+	require.Equal(http.StatusText(http.StatusBadRequest), problem.Title)
+	require.Equal(http.StatusBadRequest, problem.Status)
+	require.Equal("invalid request body", problem.Detail)
+	require.Equal("req-1", problem.Instance)
+	require.Len(problem.Errors, 1)
+	require.Equal("name", problem.Errors[0].Field)
+	require.Empty(problem.Details)
+
+	handler = wrapper.Endpoint(func() error {
+		return errors.New("secret")
+	})
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	require.Equal(http.StatusInternalServerError, rec.Code)
+	require.NotContains(rec.Body.String(), "secret")
+	require.Contains(rec.Body.String(), `"type":"https://errors.example.com/900"`)
+}
@@ -0,0 +1,146 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/router"
+	"github.com/Falokut/go-kit/http/types"
+	"github.com/Falokut/go-kit/json"
+	"github.com/Falokut/go-kit/ratelimit"
+	"github.com/pkg/errors"
+)
+
+const (
+	retryAfterHeader         = "Retry-After"
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	forwardedForHeader       = "X-Forwarded-For"
+	realIpHeader             = "X-Real-Ip"
+)
+
+// RateLimitKeyFunc returns key of client which requests are limited together
+type RateLimitKeyFunc func(ctx context.Context, r *http.Request) (string, error)
+
+// RateLimit limits requests per key, each route is limited separately,
+// route pattern prefixed with method (e.g. "POST /api/users/:id") is used as limiter scope,
+// so limits for routes may be overridden with ratelimit.WithScopeLimit.
+// Denied requests get 429 with Retry-After header, all responses carry RateLimit-* headers
+func RateLimit(limiter *ratelimit.Limiter, keyFunc RateLimitKeyFunc) http2.Middleware {
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			key, err := keyFunc(ctx, r)
+			if err != nil {
+				return errors.WithMessage(err, "get rate limit key")
+			}
+
+			scope := r.Method + " " + router.RouteFromContext(ctx)
+			result, err := limiter.Allow(ctx, scope, key)
+			if err != nil {
+				return errors.WithMessage(err, "rate limiter allow")
+			}
+			if result.Limit > 0 {
+				header := w.Header()
+				header.Set(rateLimitLimitHeader, strconv.Itoa(result.Limit))
+				header.Set(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+				header.Set(rateLimitResetHeader, ceilSeconds(result.ResetAfter))
+			}
+			if !result.Allowed {
+				w.Header().Set(retryAfterHeader, ceilSeconds(result.RetryAfter))
+				return apierrors.NewTooManyRequestsError("too many requests")
+			}
+
+			return next(ctx, w, r)
+		}
+	}
+}
+
+// ClientIpKey uses client ip as a key.
+// X-Forwarded-For and X-Real-Ip headers are honored only if request came from trustedProxies,
+// the rightmost untrusted address from X-Forwarded-For is used
+func ClientIpKey(trustedProxies ...netip.Prefix) RateLimitKeyFunc {
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trustedProxies {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(ctx context.Context, r *http.Request) (string, error) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		remote, err := netip.ParseAddr(host)
+		if err != nil {
+			return host, nil // nolint:nilerr
+		}
+		remote = remote.Unmap()
+		if !isTrusted(remote) {
+			return remote.String(), nil
+		}
+
+		forwardedFor := r.Header.Values(forwardedForHeader)
+		for i := len(forwardedFor) - 1; i >= 0; i-- {
+			parts := strings.Split(forwardedFor[i], ",")
+			for j := len(parts) - 1; j >= 0; j-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(parts[j]))
+				if err != nil {
+					return remote.String(), nil // nolint:nilerr
+				}
+				addr = addr.Unmap()
+				if !isTrusted(addr) {
+					return addr.String(), nil
+				}
+			}
+		}
+
+		realIp, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(realIpHeader)))
+		if err == nil {
+			return realIp.Unmap().String(), nil
+		}
+		return remote.String(), nil
+	}
+}
+
+// BearerSubjectKey uses "sub" claim of jwt bearer token as a key, token signature is NOT verified,
+// so middleware must be placed after authentication. Hash of token is used for opaque tokens
+func BearerSubjectKey() RateLimitKeyFunc {
+	return func(ctx context.Context, r *http.Request) (string, error) {
+		token := types.BearerToken{}
+		err := token.FromRequestHeader(r)
+		if err != nil {
+			return "", err
+		}
+
+		parts := strings.Split(token.Token, ".")
+		if len(parts) == 3 { // nolint:mnd
+			payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+			claims := struct {
+				Sub string `json:"sub"`
+			}{}
+			if err == nil && json.Unmarshal(payload, &claims) == nil && claims.Sub != "" {
+				return "sub:" + claims.Sub, nil
+			}
+		}
+		hash := sha256.Sum256([]byte(token.Token))
+		return "token:" + hex.EncodeToString(hash[:]), nil
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,136 @@
+package response
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+const (
+	JsonContentType    = "application/json"
+	XmlContentType     = "application/xml"
+	MsgpackContentType = "application/msgpack"
+)
+
+// Codec encodes response body of a single content type
+type Codec interface {
+	ContentType() string
+	// CanEncode reports whether result can be encoded, e.g. msgpack requires generated code
+	CanEncode(result any) bool
+	Encode(w io.Writer, result any) error
+}
+
+type JsonCodec struct{}
+
+func (JsonCodec) ContentType() string {
+	return JsonContentType
+}
+
+func (JsonCodec) CanEncode(any) bool {
+	return true
+}
+
+func (JsonCodec) Encode(w io.Writer, result any) error {
+	err := json.EncodeInto(w, result)
+	if err != nil {
+		return errors.WithMessage(err, "marshal json")
+	}
+	return nil
+}
+
+type XmlCodec struct{}
+
+func (XmlCodec) ContentType() string {
+	return XmlContentType
+}
+
+func (XmlCodec) CanEncode(result any) bool {
+	kind := reflect.Indirect(reflect.ValueOf(result)).Kind()
+	return kind != reflect.Map && kind != reflect.Func && kind != reflect.Chan
+}
+
+func (XmlCodec) Encode(w io.Writer, result any) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.WithMessage(err, "write xml header")
+	}
+	err = xml.NewEncoder(w).Encode(result)
+	if err != nil {
+		return errors.WithMessage(err, "marshal xml")
+	}
+	return nil
+}
+
+// MsgpackCodec encodes results with code generated by github.com/tinylib/msgp
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) ContentType() string {
+	return MsgpackContentType
+}
+
+func (MsgpackCodec) CanEncode(result any) bool {
+	switch result.(type) {
+	case msgp.Encodable, msgp.Marshaler:
+		return true
+	default:
+		return false
+	}
+}
+
+func (MsgpackCodec) Encode(w io.Writer, result any) error {
+	switch value := result.(type) {
+	case msgp.Encodable:
+		writer := msgp.NewWriter(w)
+		err := value.EncodeMsg(writer)
+		if err != nil {
+			return errors.WithMessage(err, "marshal msgpack")
+		}
+		return writer.Flush()
+	case msgp.Marshaler:
+		data, err := value.MarshalMsg(nil)
+		if err != nil {
+			return errors.WithMessage(err, "marshal msgpack")
+		}
+		_, err = w.Write(data)
+		return err
+	default:
+		return errors.Errorf("type %T does not implement msgp.Encodable", result)
+	}
+}
+
+// ProtoJsonCodec encodes protobuf messages to json with canonical protobuf mapping,
+// kit does not depend on protobuf runtime, so marshal function must be provided:
+//
+//	response.ProtoJsonCodec{Marshal: func(result any) ([]byte, error) {
+//		return protojson.Marshal(result.(proto.Message))
+//	}}
+//
+// Place it before JsonCodec to encode protobuf messages requested as application/json
+type ProtoJsonCodec struct {
+	Marshal func(result any) ([]byte, error)
+}
+
+func (c ProtoJsonCodec) ContentType() string {
+	return JsonContentType
+}
+
+// CanEncode reports whether result is a protobuf message, i.e. has ProtoReflect method
+func (c ProtoJsonCodec) CanEncode(result any) bool {
+	if c.Marshal == nil || result == nil {
+		return false
+	}
+	return reflect.ValueOf(result).MethodByName("ProtoReflect").IsValid()
+}
+
+func (c ProtoJsonCodec) Encode(w io.Writer, result any) error {
+	data, err := c.Marshal(result)
+	if err != nil {
+		return errors.WithMessage(err, "marshal protobuf json")
+	}
+	_, err = w.Write(data)
+	return err
+}
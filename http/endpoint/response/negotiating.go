@@ -0,0 +1,132 @@
+package response
+
+import (
+	"cmp"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+)
+
+const (
+	acceptHeader      = "Accept"
+	contentTypeHeader = "Content-Type"
+	varyHeader        = "Vary"
+)
+
+// NegotiatingMapper chooses codec by Accept header of request.
+// Codecs are tried in order for equally preferred media ranges, the first codec is used if Accept is empty
+type NegotiatingMapper struct {
+	codecs []Codec
+	// strict makes mapper respond 406 if no codec matches Accept, otherwise the first codec is used
+	strict bool
+}
+
+func NewNegotiatingMapper(strict bool, codecs ...Codec) NegotiatingMapper {
+	return NegotiatingMapper{
+		codecs: codecs,
+		strict: strict,
+	}
+}
+
+// DefaultNegotiatingMapper supports json, xml and msgpack, json is used by default
+func DefaultNegotiatingMapper() NegotiatingMapper {
+	return NewNegotiatingMapper(false, JsonCodec{}, XmlCodec{}, MsgpackCodec{})
+}
+
+// Map writes result with the first codec which can encode it
+func (m NegotiatingMapper) Map(result any, w http.ResponseWriter) error {
+	return m.MapRequest(nil, result, w)
+}
+
+func (m NegotiatingMapper) MapRequest(r *http.Request, result any, w http.ResponseWriter) error {
+	if result == nil {
+		return nil
+	}
+
+	accept := ""
+	if r != nil {
+		accept = r.Header.Get(acceptHeader)
+		w.Header().Add(varyHeader, acceptHeader)
+	}
+	codec := m.negotiate(accept, result)
+	if codec == nil {
+		return apierrors.NewNotAcceptableError("no acceptable response content type")
+	}
+
+	w.Header().Set(contentTypeHeader, codec.ContentType())
+	return codec.Encode(w, result)
+}
+
+// nolint:ireturn
+func (m NegotiatingMapper) negotiate(accept string, result any) Codec {
+	if accept != "" {
+		ranges := parseAccept(accept)
+		for len(ranges) > 0 {
+			// ranges with the same q are equally preferred, codec order breaks the tie
+			end := 1
+			for end < len(ranges) && ranges[end].q == ranges[0].q {
+				end++
+			}
+			for _, codec := range m.codecs {
+				if codec.CanEncode(result) && slices.ContainsFunc(ranges[:end], func(r mediaRange) bool {
+					return r.matches(codec.ContentType())
+				}) {
+					return codec
+				}
+			}
+			ranges = ranges[end:]
+		}
+		if m.strict {
+			return nil
+		}
+	}
+	for _, codec := range m.codecs {
+		if codec.CanEncode(result) {
+			return codec
+		}
+	}
+	return nil
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+func (r mediaRange) matches(contentType string) bool {
+	if r.mediaType == "*/*" || r.mediaType == contentType {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(r.mediaType, "/*")
+	return ok && strings.HasPrefix(contentType, prefix+"/")
+}
+
+// parseAccept returns acceptable media ranges ordered by preference
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	slices.SortStableFunc(ranges, func(a, b mediaRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	return ranges
+}
@@ -0,0 +1,119 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/endpoint/response"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Id   int
+	Name string
+}
+
+type protoMessage struct {
+	Id int
+}
+
+func (protoMessage) ProtoReflect() {}
+
+func TestNegotiatingMapper(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		accept              string
+		strict              bool
+		result              any
+		expectedContentType string
+		expectedStatus      int
+	}{
+		{name: "no accept", result: user{Id: 1}, expectedContentType: response.JsonContentType},
+		{name: "any", accept: "*/*", result: user{Id: 1}, expectedContentType: response.JsonContentType},
+		{
+			name:                "xml preferred",
+			accept:              "application/json;q=0.5, application/xml",
+			result:              user{Id: 1},
+			expectedContentType: response.XmlContentType,
+		},
+		{
+			name:                "json wins equal q",
+			accept:              "application/xml, application/json",
+			result:              user{Id: 1},
+			expectedContentType: response.JsonContentType,
+		},
+		{
+			name:                "json wins any with equal q",
+			accept:              "text/html,application/xhtml+xml,application/xml;q=0.9,*/*",
+			result:              user{Id: 1},
+			expectedContentType: response.JsonContentType,
+		},
+		{
+			name:                "xml preferred over any",
+			accept:              "text/html,application/xml;q=0.9,*/*;q=0.8",
+			result:              user{Id: 1},
+			expectedContentType: response.XmlContentType,
+		},
+		{
+			name:                "msgpack is skipped without generated code",
+			accept:              "application/msgpack, application/json;q=0.1",
+			result:              user{Id: 1},
+			expectedContentType: response.JsonContentType,
+		},
+		{
+			name:                "protobuf json",
+			accept:              "application/json",
+			result:              protoMessage{Id: 1},
+			expectedContentType: response.JsonContentType,
+		},
+		{
+			name:                "not strict fallback",
+			accept:              "text/html",
+			result:              user{Id: 1},
+			expectedContentType: response.JsonContentType,
+		},
+		{
+			name:           "strict",
+			accept:         "text/html",
+			strict:         true,
+			result:         user{Id: 1},
+			expectedStatus: http.StatusNotAcceptable,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			protoJson := response.ProtoJsonCodec{Marshal: func(result any) ([]byte, error) {
+				return []byte(`{"proto":true}`), nil
+			}}
+			mapper := response.NewNegotiatingMapper(
+				test.strict,
+				protoJson, response.JsonCodec{}, response.XmlCodec{}, response.MsgpackCodec{},
+			)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			err := mapper.MapRequest(req, test.result, rec)
+			if test.expectedStatus != 0 {
+				var apiErr apierrors.Error
+				require.ErrorAs(err, &apiErr)
+				require.Equal(test.expectedStatus, apiErr.HttpStatusCode())
+				return
+			}
+			require.NoError(err)
+			require.Equal(test.expectedContentType, rec.Header().Get("Content-Type"))
+			if _, ok := test.result.(protoMessage); ok {
+				require.JSONEq(`{"proto":true}`, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package endpoint
+
+import (
+	"net/http"
+)
+
+// Result is a handler result which sets status code and headers,
+// Body is written by configured ResponseBodyMapper
+//
+//	func (c Controller) Create(ctx context.Context, req domain.CreateRequest) (endpoint.Result[domain.User], error) {
+//		user, err := c.service.Create(ctx, req)
+//		if err != nil {
+//			return endpoint.Result[domain.User]{}, err
+//		}
+//		return endpoint.Created("/users/"+user.Id, user), nil
+//	}
+type Result[T any] struct {
+	StatusCode int
+	Header     http.Header
+	Body       T
+}
+
+// NewResult creates result with status code
+func NewResult[T any](statusCode int, body T) Result[T] {
+	return Result[T]{
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+		Body:       body,
+	}
+}
+
+// Created creates result with 201 status code and Location header
+func Created[T any](location string, body T) Result[T] {
+	result := NewResult(http.StatusCreated, body)
+	result.Header.Set("Location", location)
+	return result
+}
+
+// Accepted creates result with 202 status code
+func Accepted[T any](body T) Result[T] {
+	return NewResult(http.StatusAccepted, body)
+}
+
+// NoContent creates result with 204 status code
+func NoContent() Result[any] {
+	return NewResult[any](http.StatusNoContent, nil)
+}
+
+// WithHeader returns copy of result with header set
+func (r Result[T]) WithHeader(name string, value string) Result[T] {
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(name, value)
+	r.Header = header
+	return r
+}
+
+func (r Result[T]) response() (int, http.Header, any) {
+	return r.StatusCode, r.Header, r.Body
+}
+
+// statusResult is implemented by Result of any type
+type statusResult interface {
+	response() (int, http.Header, any)
+}
+
+// statusWriter writes status code right before the first byte of body,
+// so body mapper is able to set headers, e.g. Content-Type
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(w.statusCode)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
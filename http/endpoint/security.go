@@ -0,0 +1,62 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+)
+
+// SecurityHeadersConfig configures SecurityHeaders middleware, empty values are not sent
+type SecurityHeadersConfig struct {
+	ContentTypeOptions    string
+	FrameOptions          string
+	ReferrerPolicy        string
+	ContentSecurityPolicy string
+	// StrictTransportSecurityMaxAge enables Strict-Transport-Security header if greater than zero
+	StrictTransportSecurityMaxAge time.Duration
+	IncludeSubDomains             bool
+}
+
+// DefaultSecurityHeadersConfig returns config suitable for json api
+func DefaultSecurityHeadersConfig() SecurityHeadersConfig {
+	return SecurityHeadersConfig{
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'; frame-ancestors 'none'",
+	}
+}
+
+// SecurityHeaders sets security related response headers before calling next
+func SecurityHeaders(cfg SecurityHeadersConfig) http2.Middleware {
+	headers := make(map[string]string)
+	setIfNotEmpty := func(name string, value string) {
+		if value != "" {
+			headers[name] = value
+		}
+	}
+	setIfNotEmpty("X-Content-Type-Options", cfg.ContentTypeOptions)
+	setIfNotEmpty("X-Frame-Options", cfg.FrameOptions)
+	setIfNotEmpty("Referrer-Policy", cfg.ReferrerPolicy)
+	setIfNotEmpty("Content-Security-Policy", cfg.ContentSecurityPolicy)
+	if cfg.StrictTransportSecurityMaxAge > 0 {
+		value := "max-age=" + strconv.Itoa(int(cfg.StrictTransportSecurityMaxAge.Seconds()))
+		if cfg.IncludeSubDomains {
+			value += "; includeSubDomains"
+		}
+		headers["Strict-Transport-Security"] = value
+	}
+
+	return func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			header := w.Header()
+			for name, value := range headers {
+				header.Set(name, value)
+			}
+			return next(ctx, w, r)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/pkg/errors"
+)
+
+const (
+	SseContentType    = "text/event-stream"
+	NdjsonContentType = "application/x-ndjson"
+)
+
+// SseEvent is a server-sent event, Data is written as is if it is a string or []byte,
+// otherwise it is encoded to json
+type SseEvent struct {
+	Id    string
+	Event string
+	Data  any
+	Retry time.Duration
+}
+
+// Sse is a handler result which writes server-sent events from channel,
+// each event is flushed to client immediately.
+// Stream ends when channel is closed or client disconnects,
+// so producer must stop writing to the channel when request context is done
+//
+//	func (c Controller) Events(ctx context.Context) (endpoint.Sse, error) {
+//		events := make(chan endpoint.SseEvent)
+//		go c.service.Subscribe(ctx, events)
+//		return endpoint.NewSse(events).WithHeartbeat(15 * time.Second), nil
+//	}
+type Sse struct {
+	Events    <-chan SseEvent
+	Heartbeat time.Duration
+}
+
+// NewSse creates server-sent events stream
+func NewSse(events <-chan SseEvent) Sse {
+	return Sse{
+		Events: events,
+	}
+}
+
+// WithHeartbeat returns copy of stream which writes comment after interval of inactivity
+// to keep connection alive through proxies
+func (s Sse) WithHeartbeat(interval time.Duration) Sse {
+	s.Heartbeat = interval
+	return s
+}
+
+func (s Sse) writeStream(ctx context.Context, w http.ResponseWriter) error {
+	w.Header().Set("X-Accel-Buffering", "no")
+	return writeStream(ctx, w, SseContentType, s.Events, s.Heartbeat, encodeSseEvent, []byte(":\n\n"))
+}
+
+// Ndjson is a handler result which writes items from channel as newline delimited json,
+// each item is flushed to client immediately.
+// Stream ends when channel is closed or client disconnects,
+// so producer must stop writing to the channel when request context is done
+type Ndjson[T any] struct {
+	Items     <-chan T
+	Heartbeat time.Duration
+}
+
+// NewNdjson creates newline delimited json stream
+func NewNdjson[T any](items <-chan T) Ndjson[T] {
+	return Ndjson[T]{
+		Items: items,
+	}
+}
+
+// WithHeartbeat returns copy of stream which writes empty line after interval of inactivity
+// to keep connection alive through proxies
+func (s Ndjson[T]) WithHeartbeat(interval time.Duration) Ndjson[T] {
+	s.Heartbeat = interval
+	return s
+}
+
+func (s Ndjson[T]) writeStream(ctx context.Context, w http.ResponseWriter) error {
+	return writeStream(ctx, w, NdjsonContentType, s.Items, s.Heartbeat, encodeNdjsonItem[T], []byte("\n"))
+}
+
+// streamResult is implemented by Sse and Ndjson of any type
+type streamResult interface {
+	writeStream(ctx context.Context, w http.ResponseWriter) error
+}
+
+func writeStream[T any](
+	ctx context.Context,
+	w http.ResponseWriter,
+	contentType string,
+	items <-chan T,
+	heartbeat time.Duration,
+	encode func(buf *bytes.Buffer, item T) error,
+	heartbeatData []byte,
+) error {
+	controller := http.NewResponseController(w)
+	// stream is long-lived, server write timeout must not interrupt it
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	err := flush(controller)
+	if err != nil {
+		return err
+	}
+
+	var ticker *time.Ticker
+	var heartbeats <-chan time.Time
+	if heartbeat > 0 {
+		ticker = time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		heartbeats = ticker.C
+	}
+
+	buf := bytes.NewBuffer(nil)
+	for {
+		select {
+		case <-ctx.Done():
+			// client disconnected, it is not an error
+			return nil
+		case <-heartbeats:
+			_, err = w.Write(heartbeatData)
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+			buf.Reset()
+			err = encode(buf, item)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(buf.Bytes())
+			if ticker != nil {
+				ticker.Reset(heartbeat)
+			}
+		}
+		if err != nil {
+			return errors.WithMessage(err, "write stream")
+		}
+		err = flush(controller)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func flush(controller *http.ResponseController) error {
+	err := controller.Flush()
+	if err != nil {
+		return errors.WithMessage(err, "flush stream")
+	}
+	return nil
+}
+
+func encodeSseEvent(buf *bytes.Buffer, event SseEvent) error {
+	if event.Id != "" {
+		writeSseField(buf, "id", event.Id)
+	}
+	if event.Event != "" {
+		writeSseField(buf, "event", event.Event)
+	}
+	if event.Retry > 0 {
+		writeSseField(buf, "retry", strconv.FormatInt(event.Retry.Milliseconds(), 10))
+	}
+
+	var data string
+	switch value := event.Data.(type) {
+	case nil:
+	case string:
+		data = value
+	case []byte:
+		data = string(value)
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return errors.WithMessage(err, "json encode sse event data")
+		}
+		data = string(encoded)
+	}
+	// multiline data is written as several data fields
+	for line := range strings.SplitSeq(data, "\n") {
+		writeSseField(buf, "data", strings.TrimSuffix(line, "\r"))
+	}
+	buf.WriteByte('\n')
+	return nil
+}
+
+func writeSseField(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func encodeNdjsonItem[T any](buf *bytes.Buffer, item T) error {
+	err := json.EncodeInto(buf, item)
+	if err != nil {
+		return errors.WithMessage(err, "json encode ndjson item")
+	}
+	// encoder may already write new line
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	return nil
+}
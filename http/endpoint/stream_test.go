@@ -0,0 +1,95 @@
+package endpoint_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/endpoint"
+	"github.com/Falokut/go-kit/http/endpoint/hlog"
+	"github.com/Falokut/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSse(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	logger := log.New(log.WithOutput(io.Discard))
+	wrapper := endpoint.DefaultWrapper(logger, hlog.Log(logger, true))
+	producerDone := make(chan struct{})
+	server := httptest.NewServer(wrapper.Endpoint(func(ctx context.Context) (endpoint.Sse, error) {
+		events := make(chan endpoint.SseEvent)
+		go func() {
+			defer close(producerDone)
+			select {
+			case events <- endpoint.SseEvent{Id: "1", Event: "update", Data: map[string]int{"value": 1}}:
+			case <-ctx.Done():
+				return
+			}
+			<-ctx.Done()
+		}()
+		return endpoint.NewSse(events).WithHeartbeat(10 * time.Millisecond), nil
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(err)
+	defer resp.Body.Close()
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal(endpoint.SseContentType, resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	lines := make([]string, 0)
+	for range 4 {
+		line, err := reader.ReadString('\n')
+		require.NoError(err)
+		lines = append(lines, line)
+	}
+	require.Equal([]string{"id: 1\n", "event: update\n", "data: {\"value\":1}\n", "\n"}, lines)
+
+	line, err := reader.ReadString('\n')
+	require.NoError(err)
+	require.Equal(":\n", line)
+
+	cancel()
+	select {
+	case <-producerDone:
+	case <-time.After(time.Second):
+		require.Fail("producer is not canceled after client disconnect")
+	}
+}
+
+func TestNdjson(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type item struct {
+		Id int
+	}
+	wrapper := endpoint.DefaultWrapper(log.New(log.WithOutput(io.Discard)), func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	})
+	handler := wrapper.Endpoint(func() (endpoint.Ndjson[item], error) {
+		items := make(chan item, 2)
+		items <- item{Id: 1}
+		items <- item{Id: 2}
+		close(items)
+		return endpoint.NewNdjson(items), nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal(endpoint.NdjsonContentType, rec.Header().Get("Content-Type"))
+	require.True(rec.Flushed)
+	require.Equal("{\"id\":1}\n{\"id\":2}\n", rec.Body.String())
+}
@@ -6,9 +6,12 @@ import (
 	"context"
 	"net/http"
 	"reflect"
+	"slices"
 
 	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/apierrors"
 	"github.com/Falokut/go-kit/http/endpoint/binder"
+	"github.com/Falokut/go-kit/http/endpoint/ws"
 	"github.com/Falokut/go-kit/log"
 	"github.com/Falokut/go-kit/validator"
 )
@@ -18,6 +21,21 @@ type HttpError interface {
 	WriteError(w http.ResponseWriter) error
 }
 
+// ProblemError represents an error that can be written as RFC 9457 problem details.
+type ProblemError interface {
+	WriteProblem(w http.ResponseWriter, opts apierrors.ProblemOptions) error
+}
+
+// ErrorFormat defines how ErrorHandler writes errors.
+type ErrorFormat string
+
+const (
+	// ErrorFormatJson writes errors as {errorCode, errorMessage, details}.
+	ErrorFormatJson ErrorFormat = ""
+	// ErrorFormatProblem writes errors as RFC 9457 application/problem+json.
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
 // RequestBinder defines an interface for binding an HTTP request body to a Go value.
 type RequestBinder interface {
 	Bind(ctx context.Context, contentType string, r *http.Request, reqBodyType reflect.Type) (reflect.Value, error)
@@ -28,6 +46,12 @@ type ResponseBodyMapper interface {
 	Map(result any, w http.ResponseWriter) error
 }
 
+// RequestBodyMapper is a ResponseBodyMapper which depends on request, e.g. negotiates content type by Accept header.
+// It is used instead of Map if implemented by wrapper body mapper.
+type RequestBodyMapper interface {
+	MapRequest(r *http.Request, result any, w http.ResponseWriter) error
+}
+
 // ParamBuilder is a function that extracts a value from an HTTP request and wraps it for use as a parameter.
 type ParamBuilder func(ctx context.Context, w http.ResponseWriter, r *http.Request) (any, error)
 
@@ -47,6 +71,10 @@ type Wrapper struct {
 	BodyMapper   ResponseBodyMapper
 	Middlewares  []http2.Middleware
 	Logger       log.Logger
+
+	ErrorFormat ErrorFormat
+	// ProblemTypeBaseUri is a prefix of problem type URIs, used with ErrorFormatProblem
+	ProblemTypeBaseUri string
 }
 
 // NewWrapper creates a new Wrapper instance with the provided parameter mappers, binder,
@@ -78,12 +106,21 @@ func (m Wrapper) Endpoint(f any) http.HandlerFunc {
 		panic(err)
 	}
 
-	handler := caller.Handle
+	return m.handlerFunc(caller.Handle)
+}
+
+func (m Wrapper) handlerFunc(handler http2.HandlerFunc) http.HandlerFunc {
 	for i := len(m.Middlewares) - 1; i >= 0; i-- {
 		handler = m.Middlewares[i](handler)
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
+		if m.ErrorFormat == ErrorFormatProblem {
+			ctx := apierrors.ProblemOptionsToContext(r.Context(), apierrors.ProblemOptions{
+				TypeBaseUri: m.ProblemTypeBaseUri,
+			})
+			r = r.WithContext(ctx)
+		}
 		err := handler(r.Context(), w, r)
 		if err != nil {
 			m.Logger.Error(r.Context(), err)
@@ -91,14 +128,23 @@ func (m Wrapper) Endpoint(f any) http.HandlerFunc {
 	}
 }
 
+// WebSocket converts websocket handler into an http.HandlerFunc,
+// handshake request is passed through wrapper middlewares, e.g. request id, logging and auth.
+func (m Wrapper) WebSocket(handler ws.Handler, opts ...ws.Option) http.HandlerFunc {
+	return m.handlerFunc(ws.Upgrade(handler, m.Logger, opts...))
+}
+
 // WithMiddlewares adds additional HTTP middlewares to the wrapper.
 func (m Wrapper) WithMiddlewares(middlewares ...http2.Middleware) Wrapper {
 	return Wrapper{
 		ParamMappers: m.ParamMappers,
 		Binder:       m.Binder,
 		BodyMapper:   m.BodyMapper,
-		Middlewares:  append(m.Middlewares, middlewares...),
+		Middlewares:  slices.Concat(m.Middlewares, middlewares),
 		Logger:       m.Logger,
+
+		ErrorFormat:        m.ErrorFormat,
+		ProblemTypeBaseUri: m.ProblemTypeBaseUri,
 	}
 }
 
@@ -110,5 +156,22 @@ func (m Wrapper) WithValidator(validator validator.Adapter) Wrapper {
 		BodyMapper:   m.BodyMapper,
 		Middlewares:  m.Middlewares,
 		Logger:       m.Logger,
+
+		ErrorFormat:        m.ErrorFormat,
+		ProblemTypeBaseUri: m.ProblemTypeBaseUri,
 	}
 }
+
+// WithBodyMapper returns a copy of the wrapper using a new response body mapper.
+func (m Wrapper) WithBodyMapper(bodyMapper ResponseBodyMapper) Wrapper {
+	m.BodyMapper = bodyMapper
+	return m
+}
+
+// WithProblemDetails returns a copy of the wrapper which writes errors as RFC 9457 problem details.
+// Problem type is typeBaseUri + "/" + error code, "about:blank" is used if typeBaseUri is empty.
+func (m Wrapper) WithProblemDetails(typeBaseUri string) Wrapper {
+	m.ErrorFormat = ErrorFormatProblem
+	m.ProblemTypeBaseUri = typeBaseUri
+	return m
+}
@@ -0,0 +1,131 @@
+package ws
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/coder/websocket"
+	"github.com/pkg/errors"
+)
+
+type (
+	MessageType = websocket.MessageType
+	StatusCode  = websocket.StatusCode
+	CloseError  = websocket.CloseError
+)
+
+const (
+	MessageText   = websocket.MessageText
+	MessageBinary = websocket.MessageBinary
+)
+
+// Conn is an accepted websocket connection
+type Conn struct {
+	conn    *websocket.Conn
+	request *http.Request
+	cfg     config
+}
+
+// Request returns handshake request
+func (c *Conn) Request() *http.Request {
+	return c.request
+}
+
+// Subprotocol returns negotiated subprotocol, empty if no subprotocol is used
+func (c *Conn) Subprotocol() string {
+	return c.conn.Subprotocol()
+}
+
+// Read reads the next message, blocks until message is received, ctx is done or connection is closed
+func (c *Conn) Read(ctx context.Context) (MessageType, []byte, error) {
+	messageType, data, err := c.conn.Read(ctx)
+	if err != nil {
+		return 0, nil, errors.WithMessage(err, "read websocket message")
+	}
+	return messageType, data, nil
+}
+
+// Write writes message with write timeout
+func (c *Conn) Write(ctx context.Context, messageType MessageType, data []byte) error {
+	ctx, cancel := c.writeContext(ctx)
+	defer cancel()
+
+	err := c.conn.Write(ctx, messageType, data)
+	if err != nil {
+		return errors.WithMessage(err, "write websocket message")
+	}
+	return nil
+}
+
+// ReadJson reads the next message and decodes it from json into v.
+// Connection is closed with StatusUnsupportedData if message is not valid json
+func (c *Conn) ReadJson(ctx context.Context, v any) error {
+	_, data, err := c.Read(ctx)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		_ = c.conn.Close(websocket.StatusUnsupportedData, "invalid json message")
+		return errors.WithMessage(err, "json decode websocket message")
+	}
+	return nil
+}
+
+// WriteJson encodes v to json and writes it as text message
+func (c *Conn) WriteJson(ctx context.Context, v any) error {
+	buf := bytes.NewBuffer(nil)
+	err := json.EncodeInto(buf, v)
+	if err != nil {
+		return errors.WithMessage(err, "json encode websocket message")
+	}
+	return c.Write(ctx, MessageText, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// Close performs close handshake with status and reason, reason must be at most 123 bytes.
+// Connection is closed automatically when handler returns, so Close is used only to send custom status
+func (c *Conn) Close(code StatusCode, reason string) error {
+	err := c.conn.Close(code, reason)
+	if err != nil {
+		return errors.WithMessage(err, "close websocket")
+	}
+	return nil
+}
+
+func (c *Conn) writeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.cfg.writeTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.cfg.writeTimeout)
+}
+
+// Channel is a connection which exchanges json messages of In and Out types
+type Channel[In any, Out any] struct {
+	*Conn
+}
+
+// NewChannel creates typed channel over connection
+func NewChannel[In any, Out any](conn *Conn) Channel[In, Out] {
+	return Channel[In, Out]{
+		Conn: conn,
+	}
+}
+
+// Receive reads the next message
+func (c Channel[In, Out]) Receive(ctx context.Context) (In, error) {
+	var in In
+	err := c.ReadJson(ctx, &in)
+	return in, err
+}
+
+// Send writes message
+func (c Channel[In, Out]) Send(ctx context.Context, out Out) error {
+	return c.WriteJson(ctx, out)
+}
+
+// CloseStatus returns status of close error received from peer, -1 if err is not a close error
+func CloseStatus(err error) StatusCode {
+	return websocket.CloseStatus(err)
+}
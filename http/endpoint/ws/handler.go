@@ -0,0 +1,129 @@
+// Package ws provides websocket endpoints with json framing, keepalive and graceful close.
+//
+// Handshake request is passed through middlewares of endpoint.Wrapper,
+// so request id, logging and auth are applied before connection is upgraded:
+//
+//	wrapper.WebSocket(ws.Typed(func(ctx context.Context, ch ws.Channel[domain.Command, domain.Event]) error {
+//		for {
+//			cmd, err := ch.Receive(ctx)
+//			if err != nil {
+//				return err
+//			}
+//			err = ch.Send(ctx, service.Handle(ctx, cmd))
+//			if err != nil {
+//				return err
+//			}
+//		}
+//	}))
+package ws
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/log"
+	"github.com/coder/websocket"
+	"github.com/pkg/errors"
+)
+
+// Handler serves accepted websocket connection, connection is closed when handler returns.
+// Close from peer returned as error of Read is a normal completion.
+// ctx is canceled if peer does not respond to pings
+type Handler func(ctx context.Context, conn *Conn) error
+
+// Typed adapts handler of typed json channel
+func Typed[In any, Out any](handler func(ctx context.Context, ch Channel[In, Out]) error) Handler {
+	return func(ctx context.Context, conn *Conn) error {
+		return handler(ctx, NewChannel[In, Out](conn))
+	}
+}
+
+// Upgrade returns handler which upgrades connection to websocket and calls handler.
+// Handshake errors are written to response by Upgrade,
+// errors of handler are logged and connection is closed with StatusInternalError,
+// so returned error is always nil
+func Upgrade(handler Handler, logger log.Logger, opts ...Option) http2.HandlerFunc {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	acceptOpts := &websocket.AcceptOptions{
+		Subprotocols:   cfg.subprotocols,
+		OriginPatterns: cfg.originPatterns,
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		wsConn, err := websocket.Accept(w, r, acceptOpts)
+		if err != nil {
+			// response is already written
+			logger.Warn(ctx, errors.WithMessage(err, "websocket handshake"))
+			return nil
+		}
+		wsConn.SetReadLimit(cfg.readLimit)
+		conn := &Conn{
+			conn:    wsConn,
+			request: r,
+			cfg:     cfg,
+		}
+
+		parent := ctx
+		ctx, cancel := context.WithCancel(ctx)
+		wg := sync.WaitGroup{}
+		keepaliveErr := error(nil)
+		if cfg.pingInterval > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				keepaliveErr = keepalive(ctx, wsConn, cfg)
+				if keepaliveErr != nil {
+					cancel()
+				}
+			}()
+		}
+
+		err = handler(ctx, conn)
+		cancel()
+		wg.Wait()
+
+		switch {
+		case keepaliveErr != nil:
+			logger.Debug(ctx, errors.WithMessage(keepaliveErr, "websocket keepalive"))
+			_ = wsConn.CloseNow()
+		case err == nil:
+			_ = wsConn.Close(websocket.StatusNormalClosure, "")
+		case CloseStatus(err) != -1:
+			// closed by peer
+			_ = wsConn.CloseNow()
+		case parent.Err() != nil:
+			_ = wsConn.Close(websocket.StatusGoingAway, "")
+		default:
+			logFunc := log.LogLevelFuncForError(err, logger)
+			logFunc(ctx, errors.WithMessage(err, "websocket handler"))
+			_ = wsConn.Close(websocket.StatusInternalError, "internal error")
+		}
+		return nil
+	}
+}
+
+func keepalive(ctx context.Context, conn *websocket.Conn, cfg config) error {
+	ticker := time.NewTicker(cfg.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.pongTimeout)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			return errors.WithMessage(err, "ping")
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package ws_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/http/endpoint"
+	"github.com/Falokut/go-kit/http/endpoint/ws"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/requestid"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/stretchr/testify/require"
+)
+
+type command struct {
+	Value int
+}
+
+type event struct {
+	Value     int
+	RequestId string
+}
+
+func TestWebSocket(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	logger := log.New(log.WithOutput(io.Discard))
+	auth := func(next http2.HandlerFunc) http2.HandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+			if r.URL.Query().Get("token") != "secret" {
+				return apierrors.NewUnauthorizedError("invalid token")
+			}
+			return next(ctx, w, r)
+		}
+	}
+	wrapper := endpoint.DefaultWrapper(logger, func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	}).WithMiddlewares(auth)
+	handler := wrapper.WebSocket(ws.Typed(func(ctx context.Context, ch ws.Channel[command, event]) error {
+		for {
+			cmd, err := ch.Receive(ctx)
+			if err != nil {
+				return err
+			}
+			err = ch.Send(ctx, event{Value: cmd.Value * 2, RequestId: requestid.FromContext(ctx)})
+			if err != nil {
+				return err
+			}
+		}
+	}), ws.WithReadLimit(64), ws.WithKeepalive(10*time.Millisecond, time.Second))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, resp, err := websocket.Dial(ctx, url, nil)
+	require.Error(err)
+	require.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	conn, _, err := websocket.Dial(ctx, url+"?token=secret", &websocket.DialOptions{
+		HTTPHeader: http.Header{requestid.RequestIdHeader: []string{"req-1"}},
+	})
+	require.NoError(err)
+	for i := range 3 {
+		require.NoError(wsjson.Write(ctx, conn, command{Value: i}))
+		result := event{}
+		require.NoError(wsjson.Read(ctx, conn, &result))
+		require.Equal(event{Value: i * 2, RequestId: "req-1"}, result)
+	}
+
+	err = conn.Write(ctx, websocket.MessageText, []byte(strings.Repeat("a", 100)))
+	require.NoError(err)
+	_, _, err = conn.Read(ctx)
+	require.Equal(websocket.StatusMessageTooBig, websocket.CloseStatus(err))
+}
+
+func TestWebSocket_InvalidJson(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	logger := log.New(log.WithOutput(io.Discard))
+	wrapper := endpoint.DefaultWrapper(logger, func(next http2.HandlerFunc) http2.HandlerFunc {
+		return next
+	})
+	handler := wrapper.WebSocket(ws.Typed(func(ctx context.Context, ch ws.Channel[command, event]) error {
+		_, err := ch.Receive(ctx)
+		return err
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(err)
+	require.NoError(conn.Write(ctx, websocket.MessageText, []byte("{")))
+	_, _, err = conn.Read(ctx)
+	require.Equal(websocket.StatusUnsupportedData, websocket.CloseStatus(err))
+}
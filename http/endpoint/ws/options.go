@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"time"
+)
+
+const (
+	defaultReadLimit    = 32 * 1024
+	defaultPingInterval = 30 * time.Second
+	defaultPongTimeout  = 10 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+)
+
+type config struct {
+	subprotocols   []string
+	originPatterns []string
+	readLimit      int64
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	writeTimeout   time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		readLimit:    defaultReadLimit,
+		pingInterval: defaultPingInterval,
+		pongTimeout:  defaultPongTimeout,
+		writeTimeout: defaultWriteTimeout,
+	}
+}
+
+type Option func(cfg *config)
+
+// WithSubprotocols sets supported subprotocols in order of preference
+func WithSubprotocols(subprotocols ...string) Option {
+	return func(cfg *config) {
+		cfg.subprotocols = subprotocols
+	}
+}
+
+// WithOriginPatterns allows cross-origin handshakes from hosts matching patterns, e.g. "*.example.com",
+// by default only same origin is allowed
+func WithOriginPatterns(patterns ...string) Option {
+	return func(cfg *config) {
+		cfg.originPatterns = patterns
+	}
+}
+
+// WithReadLimit sets max size of incoming message in bytes, 32KB by default.
+// Connection is closed with StatusMessageTooBig if limit is exceeded
+func WithReadLimit(limit int64) Option {
+	return func(cfg *config) {
+		cfg.readLimit = limit
+	}
+}
+
+// WithKeepalive sets interval of pings and timeout of waiting for pong, 30s and 10s by default.
+// Connection is closed if pong is not received in time, zero interval disables pings.
+// Pongs are received only while connection is read, so handler must read messages to keep connection alive
+func WithKeepalive(pingInterval time.Duration, pongTimeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.pingInterval = pingInterval
+		cfg.pongTimeout = pongTimeout
+	}
+}
+
+// WithWriteTimeout sets timeout of writing single message, 10s by default
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.writeTimeout = timeout
+	}
+}
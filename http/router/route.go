@@ -0,0 +1,46 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Falokut/go-kit/cluster"
+)
+
+type Route struct {
+	Method string
+	// Path is a full path in httprouter format, e.g. /api/users/:id
+	Path string
+	// Pattern is a path template with named parameters in braces, e.g. /api/users/{id}
+	Pattern string
+	// Handler is a registered handler wrapped with group middlewares
+	Handler http.Handler
+}
+
+// Descriptor returns endpoint descriptor for cluster, Inner, UserAuthRequired and Extra may be set by caller
+func (r Route) Descriptor() cluster.EndpointDescriptor {
+	return cluster.EndpointDescriptor{
+		Path:       r.Path,
+		HttpMethod: r.Method,
+		Handler:    r.Handler,
+	}
+}
+
+// Descriptors returns endpoint descriptors for all routes
+func Descriptors(routes []Route) []cluster.EndpointDescriptor {
+	descriptors := make([]cluster.EndpointDescriptor, 0, len(routes))
+	for _, route := range routes {
+		descriptors = append(descriptors, route.Descriptor())
+	}
+	return descriptors
+}
+
+func pattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if len(segment) > 1 && (segment[0] == ':' || segment[0] == '*') {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -3,6 +3,8 @@ package router
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -11,13 +13,28 @@ type Params = httprouter.Params
 
 type ctxKeyRoutePattern struct{}
 
+// Middleware wraps handlers registered in router or group
+type Middleware func(next http.Handler) http.Handler
+
 type Router struct {
-	router *httprouter.Router
+	router      *httprouter.Router
+	prefix      string
+	middlewares []Middleware
+	shared      *shared
+}
+
+// shared is a state common for router and all its groups
+type shared struct {
+	routes   []Route
+	autoHead bool
 }
 
 func New() *Router {
 	return &Router{
 		router: httprouter.New(),
+		shared: &shared{
+			autoHead: true,
+		},
 	}
 }
 
@@ -37,17 +54,100 @@ func (r *Router) DELETE(path string, handler http.Handler) *Router {
 	return r.Handler(http.MethodDelete, path, handler)
 }
 
+func (r *Router) PATCH(path string, handler http.Handler) *Router {
+	return r.Handler(http.MethodPatch, path, handler)
+}
+
+func (r *Router) HEAD(path string, handler http.Handler) *Router {
+	return r.Handler(http.MethodHead, path, handler)
+}
+
+func (r *Router) OPTIONS(path string, handler http.Handler) *Router {
+	return r.Handler(http.MethodOptions, path, handler)
+}
+
 func (r *Router) Handler(method string, path string, handler http.Handler) *Router {
+	fullPath := joinPath(r.prefix, path)
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handler = r.middlewares[i](handler)
+	}
+
 	// Оборачиваем handler, чтобы сохранить route pattern в context
 	wrapped := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		ctx := context.WithValue(req.Context(), ctxKeyRoutePattern{}, path)
+		ctx := context.WithValue(req.Context(), ctxKeyRoutePattern{}, fullPath)
 		handler.ServeHTTP(w, req.WithContext(ctx))
 	})
-	r.router.Handler(method, path, wrapped)
+	r.router.Handler(method, fullPath, wrapped)
+	r.shared.routes = append(r.shared.routes, Route{
+		Method:  method,
+		Path:    fullPath,
+		Pattern: pattern(fullPath),
+		Handler: wrapped,
+	})
+	return r
+}
+
+// Group returns router which registers handlers with path prefix
+// and wraps them with group middlewares after middlewares of parent groups.
+// Groups may be nested
+//
+//	api := r.Group("/api", auth)
+//	api.GET("/users/:id", getUser)             // /api/users/:id with auth
+//	api.Group("/admin", adminOnly).POST(...)   // /api/admin/... with auth and adminOnly
+func (r *Router) Group(prefix string, middlewares ...Middleware) *Router {
+	return &Router{
+		router:      r.router,
+		prefix:      joinPath(r.prefix, prefix),
+		middlewares: append(slices.Clone(r.middlewares), middlewares...),
+		shared:      r.shared,
+	}
+}
+
+// NotFound sets handler for requests which match no route
+func (r *Router) NotFound(handler http.Handler) *Router {
+	r.router.NotFound = handler
+	return r
+}
+
+// MethodNotAllowed sets handler for requests which match route with other methods only,
+// Allow header is already set when handler is called
+func (r *Router) MethodNotAllowed(handler http.Handler) *Router {
+	r.router.HandleMethodNotAllowed = true
+	r.router.MethodNotAllowed = handler
+	return r
+}
+
+// AutoOptions enables automatic responses to OPTIONS requests with Allow header, enabled by default.
+// handler is called after Allow header is set and may be used to respond to CORS preflight requests, may be nil
+func (r *Router) AutoOptions(enabled bool, handler http.Handler) *Router {
+	r.router.HandleOPTIONS = enabled
+	r.router.GlobalOPTIONS = handler
 	return r
 }
 
+// AutoHead enables handling HEAD requests by GET handlers without writing response body, enabled by default
+func (r *Router) AutoHead(enabled bool) *Router {
+	r.shared.autoHead = enabled
+	return r
+}
+
+// Routes returns registered routes in registration order
+func (r *Router) Routes() []Route {
+	return slices.Clone(r.shared.routes)
+}
+
 func (r *Router) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if r.shared.autoHead && request.Method == http.MethodHead {
+		handle, params, _ := r.router.Lookup(http.MethodHead, request.URL.Path)
+		if handle == nil {
+			handle, params, _ = r.router.Lookup(http.MethodGet, request.URL.Path)
+			if handle != nil {
+				ctx := context.WithValue(request.Context(), httprouter.ParamsKey, params)
+				handle(headResponseWriter{writer}, request.WithContext(ctx), params)
+				return
+			}
+		}
+	}
 	r.router.ServeHTTP(writer, request)
 }
 
@@ -71,3 +171,30 @@ func RouteFromContext(ctx context.Context) string {
 	route, _ := ctx.Value(ctxKeyRoutePattern{}).(string)
 	return route
 }
+
+func joinPath(prefix string, path string) string {
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix + path
+}
+
+// headResponseWriter discards response body of GET handler called for HEAD request
+type headResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w headResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w headResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
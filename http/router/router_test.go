@@ -0,0 +1,118 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Falokut/go-kit/http/router"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+	suite.Run(t, &RouterSuite{})
+}
+
+type RouterSuite struct {
+	suite.Suite
+}
+
+func (s *RouterSuite) Test_Group_NestedMiddlewares() {
+	r := router.New()
+	api := r.Group("/api", headerMiddleware("X-Api"))
+	admin := api.Group("/admin/", headerMiddleware("X-Admin"))
+	api.GET("/users/:id", writeRoute())
+	admin.PATCH("/users/:id", writeRoute())
+
+	rec := s.serve(r, http.MethodGet, "/api/users/1")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("/api/users/:id", rec.Body.String())
+	s.Equal("1", rec.Header().Get("X-Api"))
+	s.Empty(rec.Header().Get("X-Admin"))
+
+	rec = s.serve(r, http.MethodPatch, "/api/admin/users/1")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Equal("/api/admin/users/:id", rec.Body.String())
+	s.Equal("1", rec.Header().Get("X-Api"))
+	s.Equal("1", rec.Header().Get("X-Admin"))
+}
+
+func (s *RouterSuite) Test_AutoHead() {
+	r := router.New()
+	r.GET("/file", writeRoute())
+
+	rec := s.serve(r, http.MethodHead, "/file")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Empty(rec.Body.String())
+
+	r.AutoHead(false)
+	rec = s.serve(r, http.MethodHead, "/file")
+	s.Equal(http.StatusMethodNotAllowed, rec.Code)
+}
+
+func (s *RouterSuite) Test_AutoOptions() {
+	r := router.New()
+	r.GET("/file", writeRoute())
+	r.DELETE("/file", writeRoute())
+
+	rec := s.serve(r, http.MethodOptions, "/file")
+	s.Equal(http.StatusOK, rec.Code)
+	s.Contains(rec.Header().Get("Allow"), http.MethodGet)
+	s.Contains(rec.Header().Get("Allow"), http.MethodDelete)
+}
+
+func (s *RouterSuite) Test_NotFoundAndMethodNotAllowed() {
+	r := router.New().
+		NotFound(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})).
+		MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		}))
+	r.GET("/file", writeRoute())
+
+	s.Equal(http.StatusTeapot, s.serve(r, http.MethodGet, "/unknown").Code)
+	rec := s.serve(r, http.MethodPost, "/file")
+	s.Equal(http.StatusConflict, rec.Code)
+	s.Contains(rec.Header().Get("Allow"), http.MethodGet)
+}
+
+func (s *RouterSuite) Test_Routes() {
+	r := router.New()
+	r.Group("/api").GET("/files/*path", writeRoute()).POST("/users/:id/avatar", writeRoute())
+
+	routes := r.Routes()
+	s.Require().Len(routes, 2)
+	s.Equal(http.MethodGet, routes[0].Method)
+	s.Equal("/api/files/*path", routes[0].Path)
+	s.Equal("/api/files/{path}", routes[0].Pattern)
+	s.Equal("/api/users/{id}/avatar", routes[1].Pattern)
+
+	descriptors := router.Descriptors(routes)
+	s.Require().Len(descriptors, 2)
+	s.Equal("/api/users/:id/avatar", descriptors[1].Path)
+	s.Equal(http.MethodPost, descriptors[1].HttpMethod)
+	s.NotNil(descriptors[1].Handler)
+}
+
+func (s *RouterSuite) serve(r *router.Router, method string, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func writeRoute() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(router.RouteFromRequest(r)))
+	})
+}
+
+func headerMiddleware(header string) router.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(header, "1")
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,28 +2,46 @@ package http
 
 import (
 	"context"
-	"github.com/Falokut/go-kit/http/apierrors"
-	"github.com/Falokut/go-kit/log"
-	"github.com/pkg/errors"
+	"crypto/tls"
+	"io/fs"
 	"net"
 	"net/http"
+	"os"
+	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/Falokut/go-kit/http/apierrors"
+	"github.com/Falokut/go-kit/log"
+	"github.com/pkg/errors"
 )
 
 type service struct {
-	delegate atomic.Value
+	delegate atomic.Pointer[delegate]
+	inFlight atomic.Int64
+}
+
+// delegate is a handler set by Upgrade with count of requests it serves
+type delegate struct {
+	handler  http.Handler
+	inFlight atomic.Int64
 }
 
 func (s *service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	value, ok := s.delegate.Load().(http.Handler)
-	if !ok {
+	s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+
+	// handler is loaded once, so Upgrade does not affect requests in progress
+	value := s.delegate.Load()
+	if value == nil {
 		_ = apierrors.NewInternalServiceError(errors.New("handler is not initialized")).
 			WriteError(w)
 		return
 	}
 
-	value.ServeHTTP(w, r)
+	value.inFlight.Add(1)
+	defer value.inFlight.Add(-1)
+	value.handler.ServeHTTP(w, r)
 }
 
 type ServerOption func(*Server)
@@ -34,9 +52,31 @@ func WithServer(server *http.Server) ServerOption {
 	}
 }
 
+// WithH2C enables HTTP/2 without TLS (prior knowledge), e.g. for internal traffic
+func WithH2C() ServerOption {
+	return func(srv *Server) {
+		protocols := &http.Protocols{}
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		srv.server.Protocols = protocols
+	}
+}
+
+type ListenerConfig struct {
+	Network string     `validate:"omitempty,oneof=tcp unix" schema:"Тип сети: tcp или unix"` // default = tcp
+	Address string     `validate:"required" schema:"Адрес host:port или путь к unix сокету"`
+	Tls     *TlsConfig `schema:"Настройки TLS,Если не заданы, соединения не шифруются"`
+}
+
 type Server struct {
-	server  *http.Server
-	service *service
+	server      *http.Server
+	service     *service
+	logger      log.Logger
+	shutdownCfg shutdownConfig
+	draining    atomic.Bool
+	baseCtx     context.Context
+	cancelBase  context.CancelFunc
 }
 
 // nolint:mnd
@@ -51,25 +91,49 @@ func NewServer(logger log.Logger, opts ...ServerOption) *Server {
 				log.Any("worker", "http server"),
 			),
 		},
-		service: &service{
-			delegate: atomic.Value{},
-		},
+		service: &service{},
+		logger:  logger,
 	}
 
 	for _, opts := range opts {
 		opts(s)
 	}
 
+	// base context is canceled on Shutdown after regular requests are completed,
+	// so handlers of hijacked connections, e.g. websockets, are notified
+	s.baseCtx, s.cancelBase = context.WithCancel(context.Background())
+	if s.server.BaseContext == nil {
+		s.server.BaseContext = func(net.Listener) context.Context {
+			return s.baseCtx
+		}
+	}
 	s.server.Handler = s.service
 	return s
 }
 
-func (s *Server) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+// Upgrade sets handler for new requests, requests in progress are completed by previous handler
+func (s *Server) Upgrade(handler http.Handler) {
+	s.service.delegate.Store(&delegate{handler: handler})
 }
 
-func (s *Server) Upgrade(handler http.Handler) {
-	s.service.delegate.Store(handler)
+// UpgradeAndWait sets handler for new requests and waits until requests of previous handler are completed,
+// so resources of previous handler may be safely released
+func (s *Server) UpgradeAndWait(ctx context.Context, handler http.Handler) error {
+	prev := s.service.delegate.Swap(&delegate{handler: handler})
+	if prev == nil {
+		return nil
+	}
+	return waitZero(ctx, &prev.inFlight)
+}
+
+// InFlight returns count of requests in progress
+func (s *Server) InFlight() int64 {
+	return s.service.inFlight.Load()
+}
+
+// Draining reports whether server is shutting down
+func (s *Server) Draining() bool {
+	return s.draining.Load()
 }
 
 func (s *Server) ListenAndServe(address string) error {
@@ -91,3 +155,106 @@ func (s *Server) Serve(listener net.Listener) error {
 	}
 	return nil
 }
+
+// ListenAndServeTls serves https on address, certificate files are reloaded when changed
+func (s *Server) ListenAndServeTls(address string, cfg TlsConfig) error {
+	return s.ListenAndServeAll(ListenerConfig{
+		Address: address,
+		Tls:     &cfg,
+	})
+}
+
+// ListenAndServeAll serves on several listeners at once, e.g. public tcp with TLS and unix socket.
+// Returns when server is shut down or any listener fails, other listeners are closed in that case
+func (s *Server) ListenAndServeAll(listenerConfigs ...ListenerConfig) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listeners := make([]net.Listener, 0, len(listenerConfigs))
+	closeListeners := func() {
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+	}
+	for _, cfg := range listenerConfigs {
+		listener, err := s.listen(ctx, cfg)
+		if err != nil {
+			closeListeners()
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return s.ServeAll(listeners...)
+}
+
+// ServeAll serves on several listeners at once.
+// Returns when server is shut down or any listener fails, other listeners are closed in that case
+func (s *Server) ServeAll(listeners ...net.Listener) error {
+	if len(listeners) == 0 {
+		return errors.New("no listeners")
+	}
+	errs := make(chan error, len(listeners))
+	wg := sync.WaitGroup{}
+	for _, listener := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Serve(listener)
+		}()
+	}
+
+	err := <-errs
+	if err != nil {
+		_ = s.server.Close()
+	}
+	wg.Wait()
+	return err
+}
+
+func (s *Server) listen(ctx context.Context, cfg ListenerConfig) (net.Listener, error) {
+	network := cfg.Network
+	if network == "" {
+		network = "tcp"
+	}
+	if network == "unix" {
+		err := removeStaleSocket(cfg.Address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen(network, cfg.Address)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "listen: %s %s", network, cfg.Address)
+	}
+	if cfg.Tls == nil {
+		return listener, nil
+	}
+
+	reloader, err := NewTlsReloader(*cfg.Tls, s.logger)
+	if err != nil {
+		_ = listener.Close()
+		return nil, errors.WithMessagef(err, "tls config for %s", cfg.Address)
+	}
+	go reloader.Run(ctx)
+	return tls.NewListener(listener, reloader.TlsConfig()), nil
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithMessagef(err, "stat %s", path)
+	}
+	if info.Mode().Type() != fs.ModeSocket {
+		return errors.Errorf("%s exists and is not a socket", path)
+	}
+	err = os.Remove(path)
+	if err != nil {
+		return errors.WithMessagef(err, "remove stale socket %s", path)
+	}
+	return nil
+}
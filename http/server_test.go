@@ -0,0 +1,246 @@
+package http_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/remote"
+	"github.com/stretchr/testify/require"
+)
+
+type testCa struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCa(t *testing.T) testCa {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return testCa{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+func (ca testCa) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func newTestServer(opts ...http2.ServerOption) *http2.Server {
+	server := http2.NewServer(log.New(log.WithOutput(io.Discard)), opts...)
+	server.Upgrade(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}))
+	return server
+}
+
+func TestServer_Tls(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	dir := t.TempDir()
+	ca := newTestCa(t)
+	cfg := http2.TlsConfig{
+		CertFile:          filepath.Join(dir, "cert.pem"),
+		KeyFile:           filepath.Join(dir, "key.pem"),
+		ClientCaFile:      filepath.Join(dir, "ca.pem"),
+		RequireClientCert: true,
+	}
+	certPem, keyPem := ca.issue(t, "server-1", x509.ExtKeyUsageServerAuth)
+	require.NoError(os.WriteFile(cfg.CertFile, certPem, 0600))
+	require.NoError(os.WriteFile(cfg.KeyFile, keyPem, 0600))
+	require.NoError(os.WriteFile(cfg.ClientCaFile, ca.pem, 0600))
+
+	reloader, err := http2.NewTlsReloader(cfg, log.New(log.WithOutput(io.Discard)))
+	require.NoError(err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(err)
+	server := newTestServer()
+	go func() {
+		_ = server.ServeAll(tls.NewListener(listener, reloader.TlsConfig()))
+	}()
+	defer server.Shutdown(context.Background()) // nolint:errcheck
+
+	rootCas := x509.NewCertPool()
+	rootCas.AddCert(ca.cert)
+	clientCertPem, clientKeyPem := ca.issue(t, "client", x509.ExtKeyUsageClientAuth)
+	clientCert, err := tls.X509KeyPair(clientCertPem, clientKeyPem)
+	require.NoError(err)
+	url := "https://" + listener.Addr().String()
+
+	serverName := func(clientCerts []tls.Certificate) (string, error) {
+		conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{
+			RootCAs:      rootCas,
+			Certificates: clientCerts,
+			MinVersion:   tls.VersionTLS12,
+		})
+		if err != nil {
+			return "", err
+		}
+		defer conn.Close()
+		// client certificate is verified by server after handshake on tls 1.3
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+		if err != nil {
+			return "", err
+		}
+		_, err = conn.Read(make([]byte, 1))
+		if err != nil {
+			return "", err
+		}
+		return conn.ConnectionState().PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	_, err = serverName(nil)
+	require.Error(err)
+	name, err := serverName([]tls.Certificate{clientCert})
+	require.NoError(err)
+	require.Equal("server-1", name)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: rootCas, Certificates: []tls.Certificate{clientCert}, MinVersion: tls.VersionTLS12},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get(url)
+	require.NoError(err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal("HTTP/2.0", string(body))
+
+	certPem, keyPem = ca.issue(t, "server-2", x509.ExtKeyUsageServerAuth)
+	require.NoError(os.WriteFile(cfg.CertFile, certPem, 0600))
+	require.NoError(os.WriteFile(cfg.KeyFile, keyPem, 0600))
+	modTime := time.Now().Add(time.Second)
+	require.NoError(os.Chtimes(cfg.CertFile, modTime, modTime))
+	reloaded, err := reloader.Reload()
+	require.NoError(err)
+	require.True(reloaded)
+	name, err = serverName([]tls.Certificate{clientCert})
+	require.NoError(err)
+	require.Equal("server-2", name)
+}
+
+func TestServer_ListenAndServeAll(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	socket := filepath.Join(t.TempDir(), "server.sock")
+	server := newTestServer(http2.WithH2C())
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServeAll(
+			http2.ListenerConfig{Address: "127.0.0.1:0"},
+			http2.ListenerConfig{Network: "unix", Address: socket},
+		)
+	}()
+
+	protocols := &http.Protocols{}
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{
+		Protocols: protocols,
+		DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	require.Eventually(func() bool {
+		resp, err := client.Get("http://localhost/")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body) == "HTTP/2.0"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(server.Shutdown(context.Background()))
+	require.NoError(<-served)
+}
+
+func TestTlsConfig_Schema(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	type config struct {
+		Listeners []http2.ListenerConfig
+	}
+	schema := remote.GenerateConfigSchema(&config{})
+	require.NotNil(schema)
+	data, err := schema.MarshalJson()
+	require.NoError(err)
+
+	type property struct {
+		Title       string
+		Description string
+		Properties  map[string]property
+		Items       *property
+	}
+	root := property{}
+	require.NoError(json.Unmarshal(data, &root))
+	tlsSchema := root.Properties["listeners"].Items.Properties["tls"]
+	require.Equal("Настройки TLS", tlsSchema.Title)
+	require.Equal("Если не заданы, соединения не шифруются", tlsSchema.Description)
+
+	expected := map[string][2]string{
+		"certFile":          {"Путь к файлу сертификата (PEM)", "Файл может содержать цепочку сертификатов"},
+		"keyFile":           {"Путь к файлу приватного ключа (PEM)", ""},
+		"clientCaFile":      {"Путь к файлу CA для проверки клиентских сертификатов (mTLS)", ""},
+		"requireClientCert": {"Требовать клиентский сертификат", "Используется вместе с clientCaFile"},
+		"minVersion":        {"Минимальная версия TLS: 1.2 или 1.3", ""},
+		"reloadIntervalSec": {"Интервал проверки изменения файлов сертификатов в секундах", "Значение -1 отключает перезагрузку"},
+	}
+	require.Len(tlsSchema.Properties, len(expected))
+	for name, texts := range expected {
+		require.Equal(texts[0], tlsSchema.Properties[name].Title, name)
+		require.Equal(texts[1], tlsSchema.Properties[name].Description, name)
+	}
+}
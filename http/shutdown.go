@@ -0,0 +1,128 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/Falokut/go-kit/json"
+	"github.com/Falokut/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	drainPollInterval = 50 * time.Millisecond
+)
+
+// Readiness is a readiness flag of service, e.g. healthcheck.Registry
+type Readiness interface {
+	SetReady(ready bool)
+}
+
+type shutdownConfig struct {
+	readiness    Readiness
+	preStopDelay time.Duration
+	drainTimeout time.Duration
+}
+
+// WithReadiness sets readiness which is switched off at the beginning of Shutdown
+func WithReadiness(readiness Readiness) ServerOption {
+	return func(srv *Server) {
+		srv.shutdownCfg.readiness = readiness
+	}
+}
+
+// WithPreStopDelay sets delay between switching off readiness and closing listeners,
+// so load balancers have time to stop routing requests to the server
+func WithPreStopDelay(delay time.Duration) ServerOption {
+	return func(srv *Server) {
+		srv.shutdownCfg.preStopDelay = delay
+	}
+}
+
+// WithDrainTimeout limits waiting for requests in progress on Shutdown,
+// remaining connections are closed forcibly after timeout
+func WithDrainTimeout(timeout time.Duration) ServerOption {
+	return func(srv *Server) {
+		srv.shutdownCfg.drainTimeout = timeout
+	}
+}
+
+// Shutdown stops server gracefully:
+//  1. switches off readiness (see WithReadiness)
+//  2. waits pre-stop delay (see WithPreStopDelay)
+//  3. closes listeners, so new connections are not accepted
+//  4. waits for requests in progress until drain timeout or ctx is done
+//  5. cancels context of hijacked connections, e.g. websockets, and waits for their handlers
+//  6. closes remaining connections forcibly
+//
+// Returns error if requests are not completed in time
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.draining.Store(true)
+	defer s.cancelBase()
+	cfg := s.shutdownCfg
+	if cfg.readiness != nil {
+		cfg.readiness.SetReady(false)
+	}
+
+	if cfg.preStopDelay > 0 {
+		s.logger.Info(ctx, "http server: pre-stop delay", log.Int64("delayMs", cfg.preStopDelay.Milliseconds()))
+		select {
+		case <-ctx.Done():
+		case <-time.After(cfg.preStopDelay):
+		}
+	}
+
+	drainCtx := ctx
+	if cfg.drainTimeout > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(ctx, cfg.drainTimeout)
+		defer cancel()
+	}
+
+	err := s.server.Shutdown(drainCtx)
+	if err == nil {
+		// hijacked connections, e.g. websockets, are not tracked by http.Server
+		s.cancelBase()
+		err = waitZero(drainCtx, &s.service.inFlight)
+	}
+	if err == nil {
+		return nil
+	}
+
+	inFlight := s.InFlight()
+	closeErr := s.server.Close()
+	if closeErr != nil {
+		s.logger.Error(ctx, errors.WithMessage(closeErr, "http server: force close"))
+	}
+	return errors.WithMessagef(err, "drain http server, %d requests in progress are interrupted", inFlight)
+}
+
+// StatsHandler returns handler which writes count of requests in progress and draining flag as json,
+// it may be registered in infra server for monitoring
+func (s *Server) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			InFlight int64
+			Draining bool
+		}{
+			InFlight: s.InFlight(),
+			Draining: s.Draining(),
+		})
+	})
+}
+
+func waitZero(ctx context.Context, counter *atomic.Int64) error {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for counter.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
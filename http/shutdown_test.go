@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/healthcheck"
+	http2 "github.com/Falokut/go-kit/http"
+	"github.com/Falokut/go-kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func serveSlow(t *testing.T, server *http2.Server, release <-chan struct{}) (string, <-chan struct{}) {
+	t.Helper()
+	started := make(chan struct{}, 1)
+	server.Upgrade(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		_, _ = io.WriteString(w, "done")
+	}))
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return "http://" + listener.Addr().String(), started
+}
+
+func TestServer_Shutdown(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	logger := log.New(log.WithOutput(io.Discard))
+	registry := healthcheck.NewRegistry(logger)
+	server := http2.NewServer(logger,
+		http2.WithReadiness(registry),
+		http2.WithPreStopDelay(50*time.Millisecond),
+		http2.WithDrainTimeout(5*time.Second),
+	)
+	release := make(chan struct{})
+	url, started := serveSlow(t, server, release)
+
+	responses := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		responses <- string(body)
+	}()
+	<-started
+	require.EqualValues(1, server.InFlight())
+
+	stats := httptest.NewRecorder()
+	server.StatsHandler().ServeHTTP(stats, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.JSONEq(`{"inFlight":1,"draining":false}`, stats.Body.String())
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- server.Shutdown(context.Background())
+	}()
+	require.Eventually(func() bool {
+		return !registry.Ready() && server.Draining()
+	}, time.Second, 5*time.Millisecond)
+	health := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(health, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(http.StatusServiceUnavailable, health.Code)
+
+	close(release)
+	require.Equal("done", <-responses)
+	require.NoError(<-shutdownErr)
+	require.Zero(server.InFlight())
+}
+
+func TestServer_Shutdown_DrainTimeout(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	server := http2.NewServer(log.New(log.WithOutput(io.Discard)), http2.WithDrainTimeout(50*time.Millisecond))
+	release := make(chan struct{})
+	defer close(release)
+	url, started := serveSlow(t, server, release)
+
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	err := server.Shutdown(context.Background())
+	require.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func TestServer_UpgradeAndWait(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	server := http2.NewServer(log.New(log.WithOutput(io.Discard)))
+	release := make(chan struct{})
+	url, started := serveSlow(t, server, release)
+	defer server.Shutdown(context.Background()) // nolint:errcheck
+
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	upgraded := make(chan error, 1)
+	go func() {
+		upgraded <- server.UpgradeAndWait(context.Background(), http.NotFoundHandler())
+	}()
+
+	resp, err := http.Get(url)
+	require.NoError(err)
+	require.NoError(resp.Body.Close())
+	require.Equal(http.StatusNotFound, resp.StatusCode)
+
+	select {
+	case <-upgraded:
+		require.Fail("upgrade must wait for requests of previous handler")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	require.NoError(<-upgraded)
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Falokut/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTlsReloadInterval = 10 * time.Second
+)
+
+type TlsConfig struct {
+	CertFile          string `validate:"required" schema:"Путь к файлу сертификата (PEM),Файл может содержать цепочку сертификатов"`
+	KeyFile           string `validate:"required" schema:"Путь к файлу приватного ключа (PEM)"`
+	ClientCaFile      string `schema:"Путь к файлу CA для проверки клиентских сертификатов (mTLS)"`
+	RequireClientCert bool   `schema:"Требовать клиентский сертификат,Используется вместе с clientCaFile"`
+	MinVersion        string `validate:"omitempty,oneof=1.2 1.3" schema:"Минимальная версия TLS: 1.2 или 1.3"`                        // default = 1.2
+	ReloadIntervalSec int    `schema:"Интервал проверки изменения файлов сертификатов в секундах,Значение -1 отключает перезагрузку"` // default = 10
+}
+
+// TlsReloader loads certificate and client CA from files and reloads them when files change,
+// handshakes in progress keep using previous certificate
+type TlsReloader struct {
+	cfg    TlsConfig
+	logger log.Logger
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCas atomic.Pointer[x509.CertPool]
+	lock      sync.Mutex
+	modTimes  map[string]time.Time
+}
+
+// NewTlsReloader loads certificate and client CA, returns error if files are invalid
+func NewTlsReloader(cfg TlsConfig, logger log.Logger) (*TlsReloader, error) {
+	r := &TlsReloader{
+		cfg:      cfg,
+		logger:   logger,
+		modTimes: make(map[string]time.Time),
+	}
+	_, err := r.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TlsConfig returns config which always uses the latest loaded certificate and client CA
+func (r *TlsReloader) TlsConfig() *tls.Config {
+	minVersion := uint16(tls.VersionTLS12)
+	if r.cfg.MinVersion == "1.3" {
+		minVersion = tls.VersionTLS13
+	}
+
+	clientAuth := tls.NoClientCert
+	if r.cfg.ClientCaFile != "" {
+		clientAuth = tls.VerifyClientCertIfGiven
+		if r.cfg.RequireClientCert {
+			clientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+
+	config := &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		},
+		ClientAuth: clientAuth,
+	}
+	if r.cfg.ClientCaFile == "" {
+		return config
+	}
+
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientConfig := config.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = r.clientCas.Load()
+		return clientConfig, nil
+	}
+	return config
+}
+
+// Reload loads files if they were modified since the last load, returns true if anything is reloaded
+func (r *TlsReloader) Reload() (bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	certChanged, err := r.changed(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return false, err
+	}
+	caChanged := false
+	if r.cfg.ClientCaFile != "" {
+		caChanged, err = r.changed(r.cfg.ClientCaFile)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if certChanged {
+		cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return false, errors.WithMessage(err, "load certificate")
+		}
+		r.cert.Store(&cert)
+	}
+	if caChanged {
+		pool, err := loadCertPool(r.cfg.ClientCaFile)
+		if err != nil {
+			return false, err
+		}
+		r.clientCas.Store(pool)
+	}
+
+	for _, file := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCaFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err == nil {
+			r.modTimes[file] = info.ModTime()
+		}
+	}
+	return certChanged || caChanged, nil
+}
+
+// Run checks files every ReloadIntervalSec until ctx is done, failed reloads are logged and previous files are used
+func (r *TlsReloader) Run(ctx context.Context) {
+	interval := defaultTlsReloadInterval
+	if r.cfg.ReloadIntervalSec < 0 {
+		return
+	}
+	if r.cfg.ReloadIntervalSec > 0 {
+		interval = time.Duration(r.cfg.ReloadIntervalSec) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := r.Reload()
+		if err != nil {
+			r.logger.Error(ctx, errors.WithMessage(err, "reload tls certificates"))
+			continue
+		}
+		if reloaded {
+			r.logger.Info(ctx, "tls certificates reloaded", log.String("certFile", r.cfg.CertFile))
+		}
+	}
+}
+
+func (r *TlsReloader) changed(files ...string) (bool, error) {
+	changed := false
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, errors.WithMessagef(err, "stat %s", file)
+		}
+		modTime, ok := r.modTimes[file]
+		if !ok || !modTime.Equal(info.ModTime()) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func loadCertPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "read %s", file)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("no certificates found in %s", file)
+	}
+	return pool, nil
+}
@@ -3,11 +3,23 @@ package types
 import (
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
 
+	"github.com/Falokut/go-kit/http/apierrors"
 	"github.com/pkg/errors"
 )
 
+const (
+	DispositionAttachment = "attachment"
+	DispositionInline     = "inline"
+)
+
+// PartialDataInfo задаёт диапазон байт файла, RangeEndByte включается в диапазон,
+// OpenEnd или значение за концом файла означает диапазон до конца файла
 type PartialDataInfo struct {
 	RangeStartByte int64
 	RangeEndByte   int64
@@ -15,10 +27,18 @@ type PartialDataInfo struct {
 
 type FileData struct {
 	PartialDataInfo *PartialDataInfo
-	PrettyName      string
-	ContentType     string
-	TotalFileSize   int64
-	ContentReader   io.ReadSeekCloser
+	// Ranges используются для ответа multipart/byteranges, если запрошено несколько диапазонов,
+	// ContentReader должен содержать весь файл
+	Ranges        []ByteRange
+	PrettyName    string
+	ContentType   string
+	TotalFileSize int64
+	ContentReader io.ReadSeekCloser
+	// ETag в кавычках, например `"abc"` или `W/"abc"`
+	ETag         string
+	LastModified time.Time
+	// Disposition тип Content-Disposition, по умолчанию attachment
+	Disposition string
 }
 
 // Write отправляет содержимое файла в ResponseWriter, поддерживая частичные загрузки.
@@ -28,9 +48,43 @@ func (file *FileData) Write(w http.ResponseWriter) error {
 	}
 	defer file.ContentReader.Close()
 
+	return file.write(w)
+}
+
+// WriteRequest отправляет содержимое файла с учётом условных заголовков запроса
+// (If-None-Match, If-Modified-Since, If-Range) и заголовка Range, если диапазоны не заданы явно.
+func (file *FileData) WriteRequest(r *http.Request, w http.ResponseWriter) error {
+	if file.ContentReader == nil {
+		return errors.New("ContentReader is nil")
+	}
+	defer file.ContentReader.Close()
+
+	if file.notModified(r) {
+		file.setValidatorHeaders(w)
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	if file.PartialDataInfo == nil && len(file.Ranges) == 0 && rangeHeader != "" && file.rangeAllowed(r) {
+		rangeOption := RangeOption{}
+		err := rangeOption.FromHeader(rangeHeader)
+		if err != nil {
+			return err
+		}
+		file.Ranges = rangeOption.Ranges
+	}
+
+	return file.write(w)
+}
+
+func (file *FileData) write(w http.ResponseWriter) error {
 	if file.PartialDataInfo != nil {
 		return file.writePartialData(w)
 	}
+	if len(file.Ranges) > 0 {
+		return file.writeRanges(w)
+	}
 
 	file.setHeaders(w)
 	w.WriteHeader(http.StatusOK)
@@ -50,15 +104,20 @@ func (file *FileData) writePartialData(w http.ResponseWriter) error {
 	if start >= file.TotalFileSize {
 		return errors.New("start byte out of range")
 	}
-	if end == 0 || end >= file.TotalFileSize {
-		end = file.TotalFileSize - 1
+	if end < start {
+		return ErrInvalidRange
 	}
+	end = min(end, file.TotalFileSize-1)
+
+	return file.writeRange(w, start, end)
+}
 
+// writeRange отправляет диапазон файла от start до end включительно, границы должны быть проверены.
+func (file *FileData) writeRange(w http.ResponseWriter, start int64, end int64) error {
 	contentLength := end - start + 1
 
 	file.setHeaders(w)
-	w.Header().Set("Accept-Ranges", "bytes")
-	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, file.TotalFileSize))
+	w.Header().Set("Content-Range", contentRange(start, end, file.TotalFileSize))
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", contentLength))
 	w.WriteHeader(http.StatusPartialContent)
 
@@ -75,10 +134,179 @@ func (file *FileData) writePartialData(w http.ResponseWriter) error {
 	return nil
 }
 
+// writeRanges отправляет запрошенные диапазоны, несколько диапазонов отправляются как multipart/byteranges.
+func (file *FileData) writeRanges(w http.ResponseWriter) error {
+	bounds := make([][2]int64, 0, len(file.Ranges))
+	for _, byteRange := range file.Ranges {
+		start, end, ok := byteRange.Bounds(file.TotalFileSize)
+		if ok {
+			bounds = append(bounds, [2]int64{start, end})
+		}
+	}
+	if len(bounds) == 0 {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", file.TotalFileSize))
+		return apierrors.NewRangeUnacceptableError("range not satisfiable")
+	}
+	if len(bounds) == 1 {
+		return file.writeRange(w, bounds[0][0], bounds[0][1])
+	}
+
+	file.setHeaders(w)
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	w.WriteHeader(http.StatusPartialContent)
+
+	for _, bound := range bounds {
+		header := textproto.MIMEHeader{}
+		if file.ContentType != "" {
+			header.Set("Content-Type", file.ContentType)
+		}
+		header.Set("Content-Range", contentRange(bound[0], bound[1], file.TotalFileSize))
+		part, err := mw.CreatePart(header)
+		if err != nil {
+			return errors.WithMessage(err, "create range part")
+		}
+
+		_, err = file.ContentReader.Seek(bound[0], io.SeekStart)
+		if err != nil {
+			return errors.WithMessage(err, "failed to seek start byte")
+		}
+		_, err = io.CopyN(part, file.ContentReader, bound[1]-bound[0]+1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return errors.WithMessage(err, "error during content copying")
+		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return errors.WithMessage(err, "close multipart writer")
+	}
+	return nil
+}
+
 // setHeaders устанавливает основные HTTP-заголовки для файла.
 func (file *FileData) setHeaders(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Accept-Ranges", "bytes")
+	file.setValidatorHeaders(w)
 	if file.PrettyName != "" {
-		w.Header().Set("Content-Disposition", fmt.Sprintf(`filename="%s"`, file.PrettyName))
+		disposition := file.Disposition
+		if disposition == "" {
+			disposition = DispositionAttachment
+		}
+		w.Header().Set("Content-Disposition", ContentDisposition(disposition, file.PrettyName))
 	}
 }
+
+func (file *FileData) setValidatorHeaders(w http.ResponseWriter) {
+	if file.ETag != "" {
+		w.Header().Set("ETag", file.ETag)
+	}
+	if !file.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", file.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified проверяет If-None-Match и If-Modified-Since (RFC 9110 13.2.2).
+func (file *FileData) notModified(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch != "" {
+		return file.ETag != "" && matchETag(ifNoneMatch, file.ETag, false)
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || file.LastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !file.LastModified.Truncate(time.Second).After(since)
+}
+
+// rangeAllowed проверяет If-Range, при несовпадении отправляется весь файл.
+func (file *FileData) rangeAllowed(r *http.Request) bool {
+	ifRange := r.Header.Get("If-Range")
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return file.ETag != "" && matchETag(ifRange, file.ETag, true)
+	}
+	date, err := http.ParseTime(ifRange)
+	if err != nil || file.LastModified.IsZero() {
+		return false
+	}
+	return file.LastModified.Truncate(time.Second).Equal(date)
+}
+
+// matchETag сравнивает etag со списком из заголовка, strong сравнение не допускает слабые etag.
+func matchETag(header string, etag string, strong bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return !strong
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strong && strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentDisposition формирует заголовок Content-Disposition,
+// не ASCII имя файла передаётся в параметре filename* (RFC 5987), filename содержит ASCII замену.
+func ContentDisposition(disposition string, filename string) string {
+	fallback := strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	header := fmt.Sprintf(`%s; filename="%s"`, disposition, fallback)
+	if fallback != filename {
+		header += "; filename*=UTF-8''" + encodeExtValue(filename)
+	}
+	return header
+}
+
+// encodeExtValue кодирует значение в percent-encoding, оставляя только attr-char (RFC 5987 3.2.1).
+func encodeExtValue(value string) string {
+	const hex = "0123456789ABCDEF"
+	builder := strings.Builder{}
+	for i := range len(value) {
+		c := value[i]
+		if isAttrChar(c) {
+			builder.WriteByte(c)
+			continue
+		}
+		builder.WriteByte('%')
+		builder.WriteByte(hex[c>>4])
+		builder.WriteByte(hex[c&0x0f])
+	}
+	return builder.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+	}
+}
+
+func contentRange(start int64, end int64, size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", start, end, size)
+}
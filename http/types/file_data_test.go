@@ -0,0 +1,192 @@
+package types_test
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/http/types"
+	"github.com/stretchr/testify/require"
+)
+
+type readSeekCloser struct {
+	*strings.Reader
+}
+
+func (readSeekCloser) Close() error {
+	return nil
+}
+
+func newFileData(content string) *types.FileData {
+	return &types.FileData{
+		PrettyName:    "отчёт 2024.txt",
+		ContentType:   "text/plain",
+		TotalFileSize: int64(len(content)),
+		ContentReader: readSeekCloser{strings.NewReader(content)},
+		ETag:          `"v1"`,
+		LastModified:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestRangeOption_FromHeader(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	option := types.RangeOption{}
+	require.NoError(option.FromHeader("bytes=0-10, 20-30,-5"))
+	require.Equal(int64(0), option.Start)
+	require.Equal(int64(10), option.End)
+	require.True(option.IsMultiple())
+	require.Equal([]types.ByteRange{{Start: 0, End: 10}, {Start: 20, End: 30}, {Start: 0, End: -5}}, option.Ranges)
+
+	require.NoError(option.FromHeader("bytes=0-0,5-"))
+	require.Equal([]types.ByteRange{{Start: 0, End: 0}, {Start: 5, End: types.OpenEnd}}, option.Ranges)
+	length, err := option.Length(10)
+	require.NoError(err)
+	require.EqualValues(1, length)
+	length, err = option.Ranges[1].Length(10)
+	require.NoError(err)
+	require.EqualValues(5, length)
+
+	require.Error(option.FromHeader("bytes=0-10,abc"))
+	require.Error(option.FromHeader("bytes=" + strings.Repeat("0-1,", types.MaxRanges) + "0-1"))
+}
+
+func TestFileData_MultipleRanges(t *testing.T) {
+	t.Parallel()
+
+	type part struct {
+		contentRange string
+		body         string
+	}
+	tests := []struct {
+		rangeHeader string
+		expected    []part
+	}{
+		{rangeHeader: "bytes=0-2,6-8", expected: []part{{"bytes 0-2/10", "012"}, {"bytes 6-8/10", "678"}}},
+		{rangeHeader: "bytes=0-0,-1", expected: []part{{"bytes 0-0/10", "0"}, {"bytes 9-9/10", "9"}}},
+		{rangeHeader: "bytes=5-5,0-0", expected: []part{{"bytes 5-5/10", "5"}, {"bytes 0-0/10", "0"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.rangeHeader, func(t *testing.T) {
+			t.Parallel()
+			require := require.New(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Range", test.rangeHeader)
+			rec := httptest.NewRecorder()
+			require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+			require.Equal(http.StatusPartialContent, rec.Code)
+
+			mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+			require.NoError(err)
+			require.Equal("multipart/byteranges", mediaType)
+			reader := multipart.NewReader(rec.Body, params["boundary"])
+			for _, part := range test.expected {
+				p, err := reader.NextPart()
+				require.NoError(err)
+				require.Equal(part.contentRange, p.Header.Get("Content-Range"))
+				require.Equal("text/plain", p.Header.Get("Content-Type"))
+				body, err := io.ReadAll(p)
+				require.NoError(err)
+				require.Equal(part.body, string(body))
+			}
+			_, err = reader.NextPart()
+			require.ErrorIs(err, io.EOF)
+		})
+	}
+}
+
+func TestFileData_SingleRange(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=-3")
+	rec := httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusPartialContent, rec.Code)
+	require.Equal("bytes 7-9/10", rec.Header().Get("Content-Range"))
+	require.Equal("789", rec.Body.String())
+
+	req.Header.Set("Range", "bytes=0-0")
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusPartialContent, rec.Code)
+	require.Equal("bytes 0-0/10", rec.Header().Get("Content-Range"))
+	require.Equal("1", rec.Header().Get("Content-Length"))
+	require.Equal("0", rec.Body.String())
+
+	req.Header.Set("Range", "bytes=5-")
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal("bytes 5-9/10", rec.Header().Get("Content-Range"))
+	require.Equal("56789", rec.Body.String())
+
+	req.Header.Set("Range", "bytes=20-30")
+	rec = httptest.NewRecorder()
+	require.Error(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal("bytes */10", rec.Header().Get("Content-Range"))
+}
+
+func TestFileData_Conditional(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"v0", W/"v1"`)
+	rec := httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusNotModified, rec.Code)
+	require.Equal(`"v1"`, rec.Header().Get("ETag"))
+	require.Empty(rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", "Tue, 02 Jan 2024 03:04:05 GMT")
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusNotModified, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 01 Jan 2024 00:00:00 GMT")
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal("Tue, 02 Jan 2024 03:04:05 GMT", rec.Header().Get("Last-Modified"))
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=0-2")
+	req.Header.Set("If-Range", `"v0"`)
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusOK, rec.Code)
+	require.Equal("0123456789", rec.Body.String())
+
+	req.Header.Set("If-Range", `"v1"`)
+	rec = httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").WriteRequest(req, rec))
+	require.Equal(http.StatusPartialContent, rec.Code)
+	require.Equal("012", rec.Body.String())
+}
+
+func TestContentDisposition(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	require.Equal(`attachment; filename="report.txt"`, types.ContentDisposition(types.DispositionAttachment, "report.txt"))
+	require.Equal(
+		`inline; filename="_____ 1_.txt"; filename*=UTF-8''%D0%BE%D1%82%D1%87%D1%91%D1%82%201%22.txt`,
+		types.ContentDisposition(types.DispositionInline, `отчёт 1".txt`),
+	)
+
+	rec := httptest.NewRecorder()
+	require.NoError(newFileData("0123456789").Write(rec))
+	_, params, err := mime.ParseMediaType(rec.Header().Get("Content-Disposition"))
+	require.NoError(err)
+	require.Equal("отчёт 2024.txt", params["filename"])
+}
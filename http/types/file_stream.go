@@ -0,0 +1,18 @@
+package types
+
+import (
+	"io"
+	"net/textproto"
+)
+
+// FileStream is a file part of multipart/form-data request which is read directly from request body.
+// Request struct may contain only one FileStream field and the file must be the last part of the form,
+// form values after the file are not bound
+type FileStream struct {
+	io.Reader
+
+	FieldName   string
+	Filename    string
+	ContentType string
+	Header      textproto.MIMEHeader
+}
@@ -2,30 +2,40 @@ package types
 
 import (
 	"errors"
+	"math"
 	"strconv"
 	"strings"
 
 	"github.com/Falokut/go-kit/http/apierrors"
 )
 
+const (
+	// MaxRanges is a max number of ranges in Range header
+	MaxRanges = 64
+	// OpenEnd is End of range without last byte position, e.g. bytes=100-
+	OpenEnd int64 = math.MaxInt64
+)
+
 var (
 	ErrInvalidRange = errors.New("invalid range: End must be greater than Start")
 )
 
-type RangeOption struct {
+// ByteRange is a single range of Range header.
+// Negative End means suffix range of -End last bytes, OpenEnd means range up to the end of object
+type ByteRange struct {
 	Start int64
 	End   int64
 }
 
-func (o *RangeOption) Length(objSize int64) (int64, error) {
+func (r ByteRange) Length(objSize int64) (int64, error) {
 	var length int64
 	switch {
-	case o.End < 0:
-		length = -o.End
-	case o.End == 0:
-		length = objSize - o.Start
+	case r.End < 0:
+		length = -r.End
+	case r.End == OpenEnd:
+		length = objSize - r.Start
 	default:
-		length = o.End - o.Start + 1
+		length = r.End - r.Start + 1
 	}
 	if length <= 0 {
 		return -1, ErrInvalidRange
@@ -33,46 +43,95 @@ func (o *RangeOption) Length(objSize int64) (int64, error) {
 	return length, nil
 }
 
-// nolint:cyclop,mnd
+// Bounds returns first and last byte positions of range in object of objSize,
+// ok is false if range is not satisfiable
+func (r ByteRange) Bounds(objSize int64) (int64, int64, bool) {
+	start, end := r.Start, r.End
+	switch {
+	case end < 0:
+		start = max(objSize+end, 0)
+		end = objSize - 1
+	case end >= objSize:
+		end = objSize - 1
+	}
+	if start >= objSize || start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// RangeOption is a parsed Range header, Start and End are bounds of the first range,
+// Ranges contains all requested ranges
+type RangeOption struct {
+	Start  int64
+	End    int64
+	Ranges []ByteRange
+}
+
+func (o *RangeOption) Length(objSize int64) (int64, error) {
+	return ByteRange{Start: o.Start, End: o.End}.Length(objSize)
+}
+
+// IsMultiple reports whether several ranges are requested
+func (o *RangeOption) IsMultiple() bool {
+	return len(o.Ranges) > 1
+}
+
 func (o *RangeOption) FromHeader(header string) error {
 	if !strings.HasPrefix(header, "bytes=") {
 		return apierrors.NewRangeUnacceptableError("invalid range format")
 	}
 
-	rangePart := strings.TrimPrefix(header, "bytes=")
-	parts := strings.Split(rangePart, "-")
+	rangeSpecs := strings.Split(strings.TrimPrefix(header, "bytes="), ",")
+	if len(rangeSpecs) > MaxRanges {
+		return apierrors.NewRangeUnacceptableError("too many ranges")
+	}
+	ranges := make([]ByteRange, 0, len(rangeSpecs))
+	for _, rangeSpec := range rangeSpecs {
+		byteRange, err := parseByteRange(strings.TrimSpace(rangeSpec))
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, byteRange)
+	}
+
+	o.Start = ranges[0].Start
+	o.End = ranges[0].End
+	o.Ranges = ranges
+	return nil
+}
+
+// nolint:cyclop,mnd
+func parseByteRange(rangeSpec string) (ByteRange, error) {
+	parts := strings.Split(rangeSpec, "-")
 	if len(parts) != 2 {
-		return apierrors.NewRangeUnacceptableError("invalid range format")
+		return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid range format")
 	}
 
 	switch {
 	case parts[0] == "" && parts[1] != "":
 		endBytes, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
 		if err != nil || endBytes <= 0 {
-			return apierrors.NewRangeUnacceptableError("invalid end byte for suffix range")
+			return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid end byte for suffix range")
 		}
-		o.Start = 0
-		o.End = -endBytes
+		return ByteRange{Start: 0, End: -endBytes}, nil
 	case parts[0] != "" && parts[1] == "":
 		startVal, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
 		if err != nil || startVal < 0 {
-			return apierrors.NewRangeUnacceptableError("invalid start byte")
+			return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid start byte")
 		}
-		o.Start = startVal
-		o.End = 0
+		return ByteRange{Start: startVal, End: OpenEnd}, nil
 	case parts[0] != "" && parts[1] != "":
 		startVal, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
 		if err != nil || startVal < 0 {
-			return apierrors.NewRangeUnacceptableError("invalid start byte")
+			return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid start byte")
 		}
 		endVal, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
 		if err != nil || endVal < startVal {
-			return apierrors.NewRangeUnacceptableError("invalid end byte or end < start")
+			return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid end byte or end < start")
 		}
-		o.Start = startVal
-		o.End = endVal
+		return ByteRange{Start: startVal, End: endVal}, nil
 	default:
-		return apierrors.NewRangeUnacceptableError("invalid range format")
+		return ByteRange{}, apierrors.NewRangeUnacceptableError("invalid range format")
 	}
-	return nil
 }
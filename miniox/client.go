@@ -2,6 +2,7 @@ package miniox
 
 import (
 	"context"
+	"io"
 	"reflect"
 	"sync/atomic"
 	"time"
@@ -17,7 +18,12 @@ var (
 	ErrMinioOffline           = errors.New("minio offline")
 )
 
-const hcDuration = 5 * time.Second
+const (
+	hcDuration = 5 * time.Second
+
+	// uploadPartSize is a size of part buffered in memory by streaming upload, minimal size allowed by s3
+	uploadPartSize = 5 * 1024 * 1024
+)
 
 type Client struct {
 	prevCfg *atomic.Value
@@ -84,3 +90,29 @@ func (c *Client) Client() (*minio.Client, error) {
 	}
 	return cli, nil
 }
+
+// UploadStream uploads object of unknown size from reader, e.g. types.FileStream from multipart request,
+// reader is uploaded part by part, so only few parts are buffered in memory at once
+func (c *Client) UploadStream(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	contentType string,
+) (minio.UploadInfo, error) {
+	cli, err := c.Client()
+	if err != nil {
+		return minio.UploadInfo{}, errors.WithMessage(err, "get client")
+	}
+
+	cfg, _ := c.prevCfg.Load().(Config)
+	info, err := cli.PutObject(ctx, bucketName, objectName, reader, -1, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    uploadPartSize,
+		NumThreads:  cfg.UploadFileThreads,
+	})
+	if err != nil {
+		return minio.UploadInfo{}, errors.WithMessage(err, "put object")
+	}
+	return info, nil
+}
@@ -0,0 +1,137 @@
+// Package ratelimit implements GCRA (generic cell rate algorithm) rate limiter,
+// which is equivalent to token bucket but stores a single timestamp per key.
+//
+// Limiter is transport agnostic, see http/endpoint.RateLimit and tg_botx/router.RateLimit middlewares.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const maxCasAttempts = 10
+
+var (
+	ErrLimitExceeded   = errors.New("rate limit exceeded")
+	ErrStoreContention = errors.New("rate limit store contention")
+)
+
+// Limit allows Count events per Period with bursts up to Burst events, Burst default = Count.
+// Limit with Count <= 0 is unlimited
+type Limit struct {
+	Count  int
+	Period time.Duration
+	Burst  int
+}
+
+func PerSecond(count int) Limit {
+	return Limit{Count: count, Period: time.Second}
+}
+
+func PerMinute(count int) Limit {
+	return Limit{Count: count, Period: time.Minute}
+}
+
+func (l Limit) unlimited() bool {
+	return l.Count <= 0 || l.Period <= 0
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Count
+}
+
+type Result struct {
+	Allowed bool
+	// Limit is a maximum number of events which may be allowed at once
+	Limit     int
+	Remaining int
+	// ResetAfter is a duration after which limit is fully restored
+	ResetAfter time.Duration
+	// RetryAfter is a duration after which next event is allowed, zero if Allowed
+	RetryAfter time.Duration
+}
+
+type Limiter struct {
+	store  Store
+	limit  Limit
+	scopes map[string]Limit
+	now    func() time.Time
+}
+
+// NewLimiter creates limiter with default limit, use WithScopeLimit to override limit for specific scopes,
+// e.g. routes or bot commands
+func NewLimiter(store Store, limit Limit, opts ...Option) *Limiter {
+	l := &Limiter{
+		store:  store,
+		limit:  limit,
+		scopes: make(map[string]Limit),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow registers event for key in scope, each scope has its own keys
+func (l *Limiter) Allow(ctx context.Context, scope string, key string) (Result, error) {
+	limit, ok := l.scopes[scope]
+	if !ok {
+		limit = l.limit
+	}
+	if limit.unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	storeKey := scope + "\x00" + key
+	// interval is at least 1ns, otherwise Count greater than Period in nanoseconds makes it zero
+	interval := max(limit.Period.Nanoseconds()/int64(limit.Count), 1)
+	burstOffset := interval * int64(limit.burst())
+	for range maxCasAttempts {
+		now := l.now().UnixNano()
+		storedTat, exists, err := l.store.Get(ctx, storeKey)
+		if err != nil {
+			return Result{}, errors.WithMessage(err, "get tat")
+		}
+
+		tat := max(storedTat, now)
+		newTat := tat + interval
+		allowAt := newTat - burstOffset
+		if now < allowAt {
+			return Result{
+				Allowed:    false,
+				Limit:      limit.burst(),
+				Remaining:  0,
+				ResetAfter: time.Duration(tat - now),
+				RetryAfter: time.Duration(allowAt - now),
+			}, nil
+		}
+
+		ttl := time.Duration(newTat - now)
+		var swapped bool
+		if exists {
+			swapped, err = l.store.CompareAndSwap(ctx, storeKey, storedTat, newTat, ttl)
+		} else {
+			swapped, err = l.store.SetIfNotExists(ctx, storeKey, newTat, ttl)
+		}
+		if err != nil {
+			return Result{}, errors.WithMessage(err, "update tat")
+		}
+		if !swapped {
+			continue
+		}
+
+		return Result{
+			Allowed:    true,
+			Limit:      limit.burst(),
+			Remaining:  int((now - allowAt) / interval),
+			ResetAfter: ttl,
+		}, nil
+	}
+	return Result{}, ErrStoreContention
+}
@@ -0,0 +1,98 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Falokut/go-kit/ratelimit"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Burst(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(0), ratelimit.Limit{Count: 1, Period: time.Hour, Burst: 3})
+	for i := range 3 {
+		result, err := limiter.Allow(ctx, "", "client")
+		require.NoError(err)
+		require.True(result.Allowed)
+		require.Equal(3, result.Limit)
+		require.Equal(2-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "", "client")
+	require.NoError(err)
+	require.False(result.Allowed)
+	require.Greater(result.RetryAfter, 59*time.Minute)
+
+	result, err = limiter.Allow(ctx, "", "other")
+	require.NoError(err)
+	require.True(result.Allowed)
+}
+
+func TestLimiter_ScopeLimit(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+
+	limiter := ratelimit.NewLimiter(
+		ratelimit.NewMemoryStore(0),
+		ratelimit.PerMinute(1),
+		ratelimit.WithScopeLimit("unlimited", ratelimit.Limit{}),
+	)
+	for range 5 {
+		result, err := limiter.Allow(ctx, "unlimited", "client")
+		require.NoError(err)
+		require.True(result.Allowed)
+	}
+
+	result, err := limiter.Allow(ctx, "limited", "client")
+	require.NoError(err)
+	require.True(result.Allowed)
+	result, err = limiter.Allow(ctx, "limited", "client")
+	require.NoError(err)
+	require.False(result.Allowed)
+}
+
+func TestLimiter_CountGreaterThanPeriod(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+	ctx := context.Background()
+
+	for _, limit := range []ratelimit.Limit{{Count: 10, Period: time.Nanosecond}, ratelimit.PerSecond(2_000_000_000)} {
+		limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(0), limit)
+		require.NotPanics(func() {
+			result, err := limiter.Allow(ctx, "", "client")
+			require.NoError(err)
+			require.True(result.Allowed)
+			require.Equal(limit.Count, result.Limit)
+			require.Less(result.Remaining, limit.Count)
+		})
+	}
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(4), ratelimit.PerMinute(50))
+	allowed := atomic.Int64{}
+	wg := sync.WaitGroup{}
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := limiter.Allow(ctx, "", "client")
+			if err == nil && result.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 50, allowed.Load())
+}
@@ -0,0 +1,19 @@
+package ratelimit
+
+type Option func(l *Limiter)
+
+// WithScopeLimit overrides default limit for scope
+func WithScopeLimit(scope string, limit Limit) Option {
+	return func(l *Limiter) {
+		l.scopes[scope] = limit
+	}
+}
+
+// WithScopeLimits overrides default limit for several scopes
+func WithScopeLimits(limits map[string]Limit) Option {
+	return func(l *Limiter) {
+		for scope, limit := range limits {
+			l.scopes[scope] = limit
+		}
+	}
+}
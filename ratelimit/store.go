@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+const (
+	defaultShards      = 64
+	memorySweepEvery   = 1024
+	memorySweepMinSize = 128
+)
+
+// Store keeps theoretical arrival time (unix nanoseconds) per key.
+// Implementations must be safe for concurrent use, e.g. redis based store may be used
+// to share limits between instances
+type Store interface {
+	Get(ctx context.Context, key string) (int64, bool, error)
+	SetIfNotExists(ctx context.Context, key string, value int64, ttl time.Duration) (bool, error)
+	CompareAndSwap(ctx context.Context, key string, old int64, value int64, ttl time.Duration) (bool, error)
+}
+
+// MemoryStore is an in-memory Store split into shards to reduce lock contention.
+// Expired keys are removed lazily
+type MemoryStore struct {
+	seed   maphash.Seed
+	shards []*memoryShard
+}
+
+type memoryShard struct {
+	lock    sync.Mutex
+	entries map[string]memoryEntry
+	ops     int
+}
+
+type memoryEntry struct {
+	value     int64
+	expiresAt int64
+}
+
+// NewMemoryStore creates store with shards count, default = 64
+func NewMemoryStore(shards int) *MemoryStore {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	s := &MemoryStore{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*memoryShard, shards),
+	}
+	for i := range s.shards {
+		s.shards[i] = &memoryShard{entries: make(map[string]memoryEntry)}
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (int64, bool, error) {
+	shard := s.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	entry, ok := shard.get(key, time.Now().UnixNano())
+	return entry.value, ok, nil
+}
+
+func (s *MemoryStore) SetIfNotExists(_ context.Context, key string, value int64, ttl time.Duration) (bool, error) {
+	shard := s.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	now := time.Now().UnixNano()
+	_, ok := shard.get(key, now)
+	if ok {
+		return false, nil
+	}
+	shard.set(key, value, now+ttl.Nanoseconds())
+	return true, nil
+}
+
+func (s *MemoryStore) CompareAndSwap(_ context.Context, key string, old int64, value int64, ttl time.Duration) (bool, error) {
+	shard := s.shard(key)
+	shard.lock.Lock()
+	defer shard.lock.Unlock()
+
+	now := time.Now().UnixNano()
+	entry, ok := shard.get(key, now)
+	if !ok || entry.value != old {
+		return false, nil
+	}
+	shard.set(key, value, now+ttl.Nanoseconds())
+	return true, nil
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+func (s *memoryShard) get(key string, now int64) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok || entry.expiresAt <= now {
+		return memoryEntry{}, false
+	}
+	return entry, true
+}
+
+func (s *memoryShard) set(key string, value int64, expiresAt int64) {
+	s.entries[key] = memoryEntry{value: value, expiresAt: expiresAt}
+	s.ops++
+	if s.ops < memorySweepEvery || len(s.entries) < memorySweepMinSize {
+		return
+	}
+	s.ops = 0
+	now := time.Now().UnixNano()
+	for key, entry := range s.entries {
+		if entry.expiresAt <= now {
+			delete(s.entries, key)
+		}
+	}
+}
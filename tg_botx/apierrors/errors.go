@@ -8,7 +8,8 @@ import (
 )
 
 const (
-	ErrCodeInternal = 900
+	ErrCodeRateLimit = 702
+	ErrCodeInternal  = 900
 )
 
 type Error struct {
@@ -3,12 +3,15 @@ package router
 import (
 	"context"
 	"fmt"
+	"math"
 	"runtime"
+	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
 
 	"github.com/Falokut/go-kit/log"
+	"github.com/Falokut/go-kit/ratelimit"
 	"github.com/Falokut/go-kit/requestid"
 	"github.com/Falokut/go-kit/tg_bot"
 	"github.com/Falokut/go-kit/tg_botx/apierrors"
@@ -112,3 +115,53 @@ func RequestId() Middleware {
 		}
 	}
 }
+
+// RateLimitKeyFunc returns key of sender which updates are limited together
+type RateLimitKeyFunc func(ctx context.Context, msg tg_bot.Update) string
+
+// SenderKey uses sender id as a key, falls back to chat id
+func SenderKey() RateLimitKeyFunc {
+	return func(ctx context.Context, msg tg_bot.Update) string {
+		sentFrom := msg.SentFrom()
+		if sentFrom != nil {
+			return strconv.FormatInt(sentFrom.Id, 10)
+		}
+		chat := msg.FromChat()
+		if chat != nil {
+			return "chat:" + strconv.FormatInt(chat.Id, 10)
+		}
+		return ""
+	}
+}
+
+// RateLimit limits updates per key, command (or update type for non-command updates) is used as limiter scope,
+// so limits for commands may be overridden with ratelimit.WithScopeLimit.
+// Updates without key are not limited
+func RateLimit(limiter *ratelimit.Limiter, keyFunc RateLimitKeyFunc) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg tg_bot.Update) (tg_bot.Chattable, error) {
+			key := keyFunc(ctx, msg)
+			if key == "" {
+				return next(ctx, msg)
+			}
+
+			scope := msg.GetCommand()
+			if scope == "" {
+				scope = msg.UpdateType()
+			}
+			result, err := limiter.Allow(ctx, scope, key)
+			if err != nil {
+				return nil, errors.WithMessage(err, "rate limiter allow")
+			}
+			if !result.Allowed {
+				retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+				return nil, apierrors.NewBusinessError(
+					apierrors.ErrCodeRateLimit,
+					fmt.Sprintf("too many requests, retry in %d seconds", retryAfter),
+					ratelimit.ErrLimitExceeded,
+				)
+			}
+			return next(ctx, msg)
+		}
+	}
+}